@@ -27,6 +27,12 @@ type WebAPIConfiguration struct {
 	DB    struct {
 		Filename string `conf:"default:service/database/wasa.db"`
 	}
+	Jobs struct {
+		Interval time.Duration `conf:"default:1m"`
+	}
+	Comments struct {
+		EditWindow time.Duration `conf:"default:15m"`
+	}
 }
 
 // loadConfiguration creates a WebAPIConfiguration starting from flags, environment variables and configuration file.
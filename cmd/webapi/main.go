@@ -111,8 +111,10 @@ func run() error {
 
 	// Create the API router
 	apirouter, err := api.New(api.Config{
-		Logger:   logger,
-		Database: db,
+		Logger:            logger,
+		Database:          db,
+		JobsInterval:      cfg.Jobs.Interval,
+		CommentEditWindow: cfg.Comments.EditWindow,
 	})
 	if err != nil {
 		logger.WithError(err).Error("error creating the API server instance")
@@ -0,0 +1,337 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+	"wasaphoto/service/database"
+	"wasaphoto/service/utils"
+)
+
+// MaxAlbumNameLength is the maximum number of characters of an album name
+const MaxAlbumNameLength = 50
+
+type Album struct {
+	Id            int64  `json:"id"`
+	Owner         User   `json:"owner"`
+	Name          string `json:"name"`
+	CoverId       *int64 `json:"coverId"`
+	PhotosCounter int    `json:"photosCounter"`
+	CreatedAt     string `json:"createdAt"`
+}
+
+func (a *Album) fromDatabase(dbAlbum database.Album) {
+	a.Id = dbAlbum.Id
+	a.Owner.fromDatabase(dbAlbum.Owner)
+	a.Name = dbAlbum.Name
+	if dbAlbum.Cover != 0 {
+		cover := dbAlbum.Cover
+		a.CoverId = &cover
+	}
+	a.PhotosCounter = dbAlbum.PhotosCounter
+	a.CreatedAt = dbAlbum.CreatedAt
+}
+
+type AlbumInfo struct {
+	Name    string `json:"name"`
+	CoverId int64  `json:"coverId"`
+}
+
+func (a AlbumInfo) IsValid() bool {
+	nameLength := utf8.RuneCountInString(a.Name)
+	return nameLength > 0 && nameLength <= MaxAlbumNameLength && a.CoverId >= 0
+}
+
+type AlbumList struct {
+	Albums []Album `json:"albums"`
+}
+
+type AlbumPage struct {
+	Album  Album   `json:"album"`
+	Photos []Photo `json:"photos"`
+}
+
+type AlbumOrder struct {
+	Photos []int64 `json:"photos"`
+}
+
+func (rt *_router) createAlbum(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	userId := params["user_id"]
+
+	var albumInfo AlbumInfo
+	err := json.NewDecoder(r.Body).Decode(&albumInfo)
+	if err != nil {
+		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Invalid request body"})
+		return
+	}
+
+	albumInfo.Name = strings.TrimSpace(albumInfo.Name)
+	if !albumInfo.IsValid() {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Invalid album name"})
+		return
+	}
+
+	albumId, dbErr := rt.db.CreateAlbum(userId, albumInfo.Name)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	dbAlbum, dbErr := rt.db.GetAlbum(albumId, userId, userId)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	var album Album
+	album.fromDatabase(dbAlbum)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(album)
+}
+
+func (rt *_router) updateAlbum(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	userId := params["user_id"]
+	albumId := params["album_id"]
+
+	if !rt.db.DoesAlbumBelongToUser(userId, albumId) {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: utils.NotUserAlbumMessage})
+		return
+	}
+
+	var albumInfo AlbumInfo
+	err := json.NewDecoder(r.Body).Decode(&albumInfo)
+	if err != nil {
+		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Invalid request body"})
+		return
+	}
+
+	albumInfo.Name = strings.TrimSpace(albumInfo.Name)
+	if !albumInfo.IsValid() {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Invalid album name"})
+		return
+	}
+
+	isOperationSuccessful, dbErr := rt.db.UpdateAlbum(albumId, userId, albumInfo.Name, albumInfo.CoverId)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	if !isOperationSuccessful {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: "The cover must be a photo of the album"})
+		return
+	}
+
+	dbAlbum, dbErr := rt.db.GetAlbum(albumId, userId, userId)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	var album Album
+	album.fromDatabase(dbAlbum)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(album)
+}
+
+func (rt *_router) deleteAlbum(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	userId := params["user_id"]
+	albumId := params["album_id"]
+
+	isOperationSuccessful, dbErr := rt.db.DeleteAlbum(albumId, userId)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	if !isOperationSuccessful {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: utils.NotUserAlbumMessage})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("Album deleted successfully"))
+}
+
+func (rt *_router) getUserAlbums(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	authUserId := params["token"]
+	userId := params["user_id"]
+
+	offset, amount, err := getPaginationParams(r)
+	if err != nil {
+		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Query paramaters badly formatted"})
+		return
+	}
+
+	userIsBanned, dbErr := rt.db.IsUserTargeted(userId, authUserId, database.BanTable)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	} else if userIsBanned {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusForbidden, Message: utils.BannedMessage})
+		return
+	}
+
+	dbAlbums, dbErr := rt.db.GetUserAlbums(userId, authUserId, amount, offset)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	albumList := AlbumList{Albums: make([]Album, 0, len(dbAlbums))}
+	for _, dbAlbum := range dbAlbums {
+		var album Album
+		album.fromDatabase(dbAlbum)
+		albumList.Albums = append(albumList.Albums, album)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(albumList)
+}
+
+func (rt *_router) getAlbum(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	authUserId := params["token"]
+	userId := params["user_id"]
+	albumId := params["album_id"]
+
+	offset, amount, err := getPaginationParams(r)
+	if err != nil {
+		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Query paramaters badly formatted"})
+		return
+	}
+
+	userIsBanned, dbErr := rt.db.IsUserTargeted(userId, authUserId, database.BanTable)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	} else if userIsBanned {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusForbidden, Message: utils.BannedMessage})
+		return
+	}
+
+	if !rt.db.DoesAlbumBelongToUser(userId, albumId) {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: utils.NotUserAlbumMessage})
+		return
+	}
+
+	dbAlbum, dbErr := rt.db.GetAlbum(albumId, userId, authUserId)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	dbPhotos, dbErr := rt.db.GetAlbumPhotos(albumId, authUserId, amount, offset)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	var albumPage AlbumPage
+	albumPage.Album.fromDatabase(dbAlbum)
+	albumPage.Photos = make([]Photo, 0, len(dbPhotos))
+	for _, dbPhoto := range dbPhotos {
+		var photo Photo
+		photo.fromDatabase(dbPhoto)
+		albumPage.Photos = append(albumPage.Photos, photo)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(albumPage)
+}
+
+func (rt *_router) addPhotoToAlbum(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	userId := params["user_id"]
+	albumId := params["album_id"]
+	photoId := params["photo_id"]
+
+	if !rt.db.DoesAlbumBelongToUser(userId, albumId) {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: utils.NotUserAlbumMessage})
+		return
+	}
+
+	if !rt.db.DoesPhotoBelongToUser(userId, photoId) {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: utils.NotUserPhotoMessage})
+		return
+	}
+
+	isOperationSuccessful, dbErr := rt.db.AddPhotoToAlbum(albumId, photoId)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	if !isOperationSuccessful {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusInternalServerError, Message: "Can't add photo to album"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("Photo added to album successfully"))
+}
+
+func (rt *_router) removePhotoFromAlbum(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	userId := params["user_id"]
+	albumId := params["album_id"]
+	photoId := params["photo_id"]
+
+	if !rt.db.DoesAlbumBelongToUser(userId, albumId) {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: utils.NotUserAlbumMessage})
+		return
+	}
+
+	isOperationSuccessful, dbErr := rt.db.RemovePhotoFromAlbum(albumId, photoId)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	if !isOperationSuccessful {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusNotFound, Message: "Photo not found in album"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("Photo removed from album successfully"))
+}
+
+func (rt *_router) reorderAlbum(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	userId := params["user_id"]
+	albumId := params["album_id"]
+
+	if !rt.db.DoesAlbumBelongToUser(userId, albumId) {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: utils.NotUserAlbumMessage})
+		return
+	}
+
+	var albumOrder AlbumOrder
+	err := json.NewDecoder(r.Body).Decode(&albumOrder)
+	if err != nil {
+		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Invalid request body"})
+		return
+	}
+
+	isOperationSuccessful, dbErr := rt.db.ReorderAlbum(albumId, albumOrder.Photos)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	if !isOperationSuccessful {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: "The new order must contain every photo of the album once"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(albumOrder)
+}
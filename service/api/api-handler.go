@@ -14,23 +14,86 @@ func (rt *_router) Handler() http.Handler {
 	rt.router.GET("/profiles/:user_id/photos/:photo_id", rt.wrap(rt.getImage))
 	rt.router.PUT("/profiles/:user_id/name", rt.wrap(rt.authWrap(rt.setMyUsername)))
 	rt.router.DELETE("/profiles/:user_id/photos/:photo_id", rt.wrap(rt.authWrap(rt.deletePhoto)))
+	rt.router.PUT("/profiles/:user_id/photos/:photo_id/alt-text", rt.wrap(rt.authWrap(rt.setPhotoAltText)))
+	rt.router.PUT("/profiles/:user_id/photos/:photo_id/visibility", rt.wrap(rt.authWrap(rt.setPhotoVisibility)))
+	rt.router.PUT("/profiles/:user_id/photos/:photo_id/comment-policy", rt.wrap(rt.authWrap(rt.setPhotoCommentPolicy)))
+	rt.router.PUT("/profiles/:user_id/photos/:photo_id/place", rt.wrap(rt.authWrap(rt.setPhotoPlace)))
+	rt.router.DELETE("/profiles/:user_id/photos/:photo_id/place", rt.wrap(rt.authWrap(rt.removePhotoPlace)))
+	rt.router.PUT("/profiles/:user_id/photos/:photo_id/archive", rt.wrap(rt.authWrap(rt.archivePhoto)))
+	rt.router.DELETE("/profiles/:user_id/photos/:photo_id/archive", rt.wrap(rt.authWrap(rt.restorePhoto)))
+	rt.router.GET("/profiles/:user_id/archive/", rt.wrap(rt.authWrap(rt.getArchivedPhotos)))
+	rt.router.GET("/profiles/:user_id/scheduled/", rt.wrap(rt.authWrap(rt.getScheduledPhotos)))
+	rt.router.PUT("/profiles/:user_id/scheduled/:photo_id", rt.wrap(rt.authWrap(rt.reschedulePhoto)))
+	rt.router.DELETE("/profiles/:user_id/scheduled/:photo_id", rt.wrap(rt.authWrap(rt.cancelScheduledPhoto)))
 	rt.router.GET("/profiles/:user_id", rt.wrap(rt.getUserProfile))
+	// Albums
+	rt.router.POST("/profiles/:user_id/albums/", rt.wrap(rt.authWrap(rt.createAlbum)))
+	rt.router.GET("/profiles/:user_id/albums", rt.wrap(rt.getUserAlbums))
+	rt.router.GET("/profiles/:user_id/albums/:album_id", rt.wrap(rt.getAlbum))
+	rt.router.PUT("/profiles/:user_id/albums/:album_id", rt.wrap(rt.authWrap(rt.updateAlbum)))
+	rt.router.DELETE("/profiles/:user_id/albums/:album_id", rt.wrap(rt.authWrap(rt.deleteAlbum)))
+	rt.router.PUT("/profiles/:user_id/albums/:album_id/photos/", rt.wrap(rt.authWrap(rt.reorderAlbum)))
+	rt.router.PUT("/profiles/:user_id/albums/:album_id/photos/:photo_id", rt.wrap(rt.authWrap(rt.addPhotoToAlbum)))
+	rt.router.DELETE("/profiles/:user_id/albums/:album_id/photos/:photo_id", rt.wrap(rt.authWrap(rt.removePhotoFromAlbum)))
+	// Saved photos
+	rt.router.PUT("/profiles/:user_id/saved/:photo_id", rt.wrap(rt.authWrap(rt.savePhoto)))
+	rt.router.DELETE("/profiles/:user_id/saved/:photo_id", rt.wrap(rt.authWrap(rt.unsavePhoto)))
+	rt.router.GET("/profiles/:user_id/saved/", rt.wrap(rt.authWrap(rt.getSavedPhotos)))
+	rt.router.GET("/profiles/:user_id/saved/collections", rt.wrap(rt.authWrap(rt.getSavedCollections)))
+	// Stories
+	rt.router.POST("/profiles/:user_id/stories/", rt.wrap(rt.authWrap(rt.postStory)))
+	rt.router.GET("/profiles/:user_id/stories/:story_id", rt.wrap(rt.getStoryImage))
+	rt.router.DELETE("/profiles/:user_id/stories/:story_id", rt.wrap(rt.authWrap(rt.deleteStory)))
+	rt.router.GET("/profiles/:user_id/stories/:story_id/viewers/", rt.wrap(rt.authWrap(rt.getStoryViewers)))
 	// Users relations
 	rt.router.PUT("/profiles/:user_id/ban/:targeted_user_id", rt.wrap(rt.authWrap(rt.banUser)))
 	rt.router.DELETE("/profiles/:user_id/ban/:targeted_user_id", rt.wrap(rt.authWrap(rt.unbanUser)))
 	rt.router.PUT("/profiles/:user_id/following/:targeted_user_id", rt.wrap(rt.authWrap(rt.followUser)))
 	rt.router.DELETE("/profiles/:user_id/following/:targeted_user_id", rt.wrap(rt.authWrap(rt.unfollowUser)))
-	rt.router.GET("/profiles/:user_id/following/", rt.wrap(rt.authWrap(rt.getFollowedUsers)))
+	rt.router.GET("/profiles/:user_id/following/", rt.wrap(rt.getFollowedUsers))
+	rt.router.GET("/profiles/:user_id/followers/", rt.wrap(rt.getFollowers))
 	rt.router.GET("/profiles/:user_id/ban/", rt.wrap(rt.authWrap(rt.getBannedUsers)))
+	rt.router.PUT("/profiles/:user_id/mute/:targeted_user_id", rt.wrap(rt.authWrap(rt.muteUser)))
+	rt.router.DELETE("/profiles/:user_id/mute/:targeted_user_id", rt.wrap(rt.authWrap(rt.unmuteUser)))
+	rt.router.GET("/profiles/:user_id/mute/", rt.wrap(rt.authWrap(rt.getMutedUsers)))
+	rt.router.PUT("/profiles/:user_id/close-friends/:targeted_user_id", rt.wrap(rt.authWrap(rt.addCloseFriend)))
+	rt.router.DELETE("/profiles/:user_id/close-friends/:targeted_user_id", rt.wrap(rt.authWrap(rt.removeCloseFriend)))
+	rt.router.GET("/profiles/:user_id/close-friends/", rt.wrap(rt.authWrap(rt.getCloseFriends)))
+	rt.router.GET("/profiles/:user_id/suggestions", rt.wrap(rt.authWrap(rt.getFollowSuggestions)))
+	rt.router.GET("/profiles/:user_id/connections/:other_id", rt.wrap(rt.authWrap(rt.getConnections)))
+	rt.router.GET("/profiles/:user_id/export", rt.wrap(rt.authWrap(rt.exportUsers)))
+	rt.router.POST("/profiles/:user_id/import", rt.wrap(rt.authWrap(rt.importUsers)))
+	rt.router.GET("/relationships", rt.wrap(rt.getRelationships))
+	rt.router.PUT("/profiles/:user_id/private", rt.wrap(rt.authWrap(rt.setAccountPrivacy)))
+	rt.router.GET("/profiles/:user_id/follow-requests/", rt.wrap(rt.authWrap(rt.getFollowRequests)))
+	rt.router.PUT("/profiles/:user_id/follow-requests/:targeted_user_id", rt.wrap(rt.authWrap(rt.approveFollowRequest)))
+	rt.router.DELETE("/profiles/:user_id/follow-requests/:targeted_user_id", rt.wrap(rt.authWrap(rt.rejectFollowRequest)))
 	// Photo interactions
 	rt.router.PUT("/profiles/:user_id/photos/:photo_id/likes/:targeted_user_id", rt.wrap(rt.likePhoto))
 	rt.router.DELETE("/profiles/:user_id/photos/:photo_id/likes/:targeted_user_id", rt.wrap(rt.unlikePhoto))
+	rt.router.GET("/profiles/:user_id/photos/:photo_id/likes/", rt.wrap(rt.getPhotoLikes))
+	rt.router.PUT("/profiles/:user_id/photos/:photo_id/reactions/:targeted_user_id", rt.wrap(rt.reactToPhoto))
+	rt.router.DELETE("/profiles/:user_id/photos/:photo_id/reactions/:targeted_user_id", rt.wrap(rt.unlikePhoto))
+	rt.router.GET("/profiles/:user_id/photos/:photo_id/reactions/", rt.wrap(rt.getPhotoReactions))
+	rt.router.PUT("/profiles/:user_id/photos/:photo_id/reposts/:targeted_user_id", rt.wrap(rt.repostPhoto))
+	rt.router.DELETE("/profiles/:user_id/photos/:photo_id/reposts/:targeted_user_id", rt.wrap(rt.unrepostPhoto))
 	rt.router.POST("/profiles/:user_id/photos/:photo_id/comments/", rt.wrap(rt.commentPhoto))
 	rt.router.DELETE("/profiles/:user_id/photos/:photo_id/comments/:comment_id", rt.wrap(rt.deleteComment))
 	rt.router.GET("/profiles/:user_id/photos/:photo_id/comments/", rt.wrap(rt.getPhotoComments))
+	rt.router.GET("/profiles/:user_id/photos/:photo_id/comments/:comment_id/replies/", rt.wrap(rt.getCommentReplies))
+	rt.router.PATCH("/profiles/:user_id/photos/:photo_id/comments/:comment_id", rt.wrap(rt.editComment))
+	rt.router.PUT("/profiles/:user_id/photos/:photo_id/comments/:comment_id/hidden", rt.wrap(rt.authWrap(rt.hideComment)))
+	rt.router.DELETE("/profiles/:user_id/photos/:photo_id/comments/:comment_id/hidden", rt.wrap(rt.authWrap(rt.unhideComment)))
+	rt.router.GET("/profiles/:user_id/photos/:photo_id/comments/:comment_id/revisions/", rt.wrap(rt.getCommentRevisions))
+	rt.router.PUT("/profiles/:user_id/photos/:photo_id/comments/:comment_id/likes/:targeted_user_id", rt.wrap(rt.likeComment))
+	rt.router.DELETE("/profiles/:user_id/photos/:photo_id/comments/:comment_id/likes/:targeted_user_id", rt.wrap(rt.unlikeComment))
 	rt.router.GET("/search", rt.wrap(rt.doSearch))
+	// Places
+	rt.router.GET("/places/nearby", rt.wrap(rt.getNearbyPhotos))
+	rt.router.GET("/places/box", rt.wrap(rt.getPlaceClusters))
 	// Stream
 	rt.router.GET("/stream/:user_id", rt.wrap(rt.authWrap(rt.getMyStream)))
+	rt.router.GET("/stream/:user_id/stories", rt.wrap(rt.authWrap(rt.getStoriesTray)))
 	// Special routes
 	rt.router.GET("/liveness", rt.liveness)
 
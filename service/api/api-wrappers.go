@@ -17,7 +17,7 @@ type httpRouterHandler func(http.ResponseWriter, *http.Request, map[string]int64
 func (rt *_router) wrap(fn httpRouterHandler) func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 
-		var entitiesId [3]int64
+		entitiesId := make([]int64, len(ps))
 		var err error
 		var dbErr database.DbError
 		params := make(map[string]int64)
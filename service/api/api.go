@@ -41,6 +41,8 @@ import (
 	"github.com/julienschmidt/httprouter"
 	"github.com/sirupsen/logrus"
 	"net/http"
+	"sync"
+	"time"
 	"wasaphoto/service/database"
 	"wasaphoto/service/utils"
 )
@@ -52,8 +54,22 @@ type Config struct {
 
 	// Database is the instance of database.AppDatabase where data are saved
 	Database database.AppDatabase
+
+	// JobsInterval is how often the background jobs (e.g., the purge of expired stories) run. Defaults to
+	// DefaultJobsInterval.
+	JobsInterval time.Duration
+
+	// CommentEditWindow is how long after posting a comment its author can edit it. Defaults to
+	// DefaultCommentEditWindow.
+	CommentEditWindow time.Duration
 }
 
+// DefaultJobsInterval is the interval used for the background jobs when none is configured
+const DefaultJobsInterval = time.Minute
+
+// DefaultCommentEditWindow is the comment edit window used when none is configured
+const DefaultCommentEditWindow = 15 * time.Minute
+
 // Router is the package API interface representing an API handler builder
 type Router interface {
 	// Handler returns an HTTP handler for APIs provided in this package
@@ -79,11 +95,26 @@ func New(cfg Config) (Router, error) {
 	router.RedirectTrailingSlash = false
 	router.RedirectFixedPath = false
 
-	return &_router{
+	if cfg.JobsInterval <= 0 {
+		cfg.JobsInterval = DefaultJobsInterval
+	}
+	if cfg.CommentEditWindow <= 0 {
+		cfg.CommentEditWindow = DefaultCommentEditWindow
+	}
+
+	rt := &_router{
 		router:     router,
 		baseLogger: cfg.Logger,
 		db:         cfg.Database,
-	}, nil
+		stopJobs:   make(chan struct{}),
+
+		commentEditWindow: cfg.CommentEditWindow,
+	}
+
+	rt.jobs.Add(1)
+	go rt.runBackgroundJobs(cfg.JobsInterval)
+
+	return rt, nil
 }
 
 type _router struct {
@@ -94,6 +125,13 @@ type _router struct {
 	baseLogger logrus.FieldLogger
 
 	db database.AppDatabase
+
+	// stopJobs is closed to stop the background jobs, jobs is used to wait for them to return
+	stopJobs chan struct{}
+	jobs     sync.WaitGroup
+
+	// commentEditWindow is how long after posting a comment its author can edit it
+	commentEditWindow time.Duration
 }
 
 func (rt *_router) LoggerAndHttpErrorSender(resWriter http.ResponseWriter, err error, errorResponse utils.HttpError) {
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"wasaphoto/service/utils"
+)
+
+func (rt *_router) archivePhoto(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	photoId := params["photo_id"]
+	userId := params["user_id"]
+
+	if !rt.db.DoesPhotoBelongToUser(userId, photoId) {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: utils.NotUserPhotoMessage})
+		return
+	}
+
+	isOperationSuccessful, dbErr := rt.db.ArchivePhoto(photoId, userId)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	if !isOperationSuccessful {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: "Photo already archived"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("Photo archived successfully"))
+}
+
+func (rt *_router) restorePhoto(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	photoId := params["photo_id"]
+	userId := params["user_id"]
+
+	if !rt.db.DoesPhotoBelongToUser(userId, photoId) {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: utils.NotUserPhotoMessage})
+		return
+	}
+
+	isOperationSuccessful, dbErr := rt.db.RestorePhoto(photoId, userId)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	if !isOperationSuccessful {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: "Photo is not archived"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("Photo restored successfully"))
+}
+
+func (rt *_router) getArchivedPhotos(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	userId := params["user_id"]
+
+	offset, amount, err := getPaginationParams(r)
+	if err != nil {
+		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Query paramaters badly formatted"})
+		return
+	}
+
+	dbPhotos, dbErr := rt.db.GetArchivedPhotos(userId, amount, offset)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	photos := make([]Photo, 0, len(dbPhotos))
+	for _, dbPhoto := range dbPhotos {
+		var photo Photo
+		photo.fromDatabase(dbPhoto)
+		photos = append(photos, photo)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(UserStream{photos})
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"time"
+)
+
+// runBackgroundJobs runs the periodic jobs every interval, until the router is closed
+func (rt *_router) runBackgroundJobs(interval time.Duration) {
+	defer rt.jobs.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-rt.stopJobs:
+			return
+		case <-ticker.C:
+			rt.purgeExpiredStories()
+			rt.publishScheduledPhotos()
+		}
+	}
+}
+
+func (rt *_router) purgeExpiredStories() {
+	purged, dbErr := rt.db.PurgeExpiredStories()
+	if dbErr.InternalError != nil {
+		rt.baseLogger.WithError(dbErr.InternalError).Error("error purging expired stories")
+		return
+	}
+
+	if purged > 0 {
+		rt.baseLogger.Infof("%d expired stories purged", purged)
+	}
+}
+
+func (rt *_router) publishScheduledPhotos() {
+	published, dbErr := rt.db.PublishScheduledPhotos()
+	if dbErr.InternalError != nil {
+		rt.baseLogger.WithError(dbErr.InternalError).Error("error publishing scheduled photos")
+		return
+	}
+
+	if published > 0 {
+		rt.baseLogger.Infof("%d scheduled photos published", published)
+	}
+}
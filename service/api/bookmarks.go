@@ -0,0 +1,140 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+	"wasaphoto/service/utils"
+)
+
+// MaxCollectionNameLength is the maximum number of characters of a bookmarks collection name
+const MaxCollectionNameLength = 30
+
+type Bookmark struct {
+	Collection string `json:"collection"`
+}
+
+func (b Bookmark) IsValid() bool {
+	return utf8.RuneCountInString(b.Collection) <= MaxCollectionNameLength
+}
+
+type SavedCollection struct {
+	Name          string `json:"name"`
+	PhotosCounter int    `json:"photosCounter"`
+}
+
+type SavedCollections struct {
+	Collections []SavedCollection `json:"collections"`
+}
+
+func (rt *_router) savePhoto(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	userId := params["user_id"]
+	photoId := params["photo_id"]
+
+	// The body is optional, without it the photo is saved outside any collection
+	var bookmark Bookmark
+	err := json.NewDecoder(r.Body).Decode(&bookmark)
+	if err != nil && !errors.Is(err, io.EOF) {
+		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Invalid request body"})
+		return
+	}
+
+	bookmark.Collection = strings.TrimSpace(bookmark.Collection)
+	if !bookmark.IsValid() {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Collection name is too long"})
+		return
+	}
+
+	photoOwner, dbErr := rt.db.GetPhotoOwner(photoId)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	if !rt.canViewPhoto(w, photoId, photoOwner, userId) {
+		return
+	}
+
+	dbErr = rt.db.SavePhoto(userId, photoId, bookmark.Collection)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(bookmark)
+}
+
+func (rt *_router) unsavePhoto(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	userId := params["user_id"]
+	photoId := params["photo_id"]
+
+	isOperationSuccessful, dbErr := rt.db.UnsavePhoto(userId, photoId)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	if !isOperationSuccessful {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusNotFound, Message: "Photo not saved"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("Photo removed from saved successfully"))
+}
+
+func (rt *_router) getSavedPhotos(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	userId := params["user_id"]
+
+	offset, amount, err := getPaginationParams(r)
+	if err != nil {
+		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Query paramaters badly formatted"})
+		return
+	}
+
+	collection := strings.TrimSpace(r.URL.Query().Get("collection"))
+	dbPhotos, dbErr := rt.db.GetSavedPhotos(userId, collection, amount, offset)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	photos := make([]Photo, 0, len(dbPhotos))
+	for _, dbPhoto := range dbPhotos {
+		var photo Photo
+		photo.fromDatabase(dbPhoto)
+		photos = append(photos, photo)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(UserStream{photos})
+}
+
+func (rt *_router) getSavedCollections(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	userId := params["user_id"]
+
+	dbCollections, dbErr := rt.db.GetSavedCollections(userId)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	collections := SavedCollections{Collections: make([]SavedCollection, 0, len(dbCollections))}
+	for _, dbCollection := range dbCollections {
+		collections.Collections = append(collections.Collections, SavedCollection{
+			Name:          dbCollection.Name,
+			PhotosCounter: dbCollection.PhotosCounter,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(collections)
+}
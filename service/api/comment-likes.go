@@ -0,0 +1,70 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"wasaphoto/service/utils"
+)
+
+func (rt *_router) likeComment(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	authUserId := params["token"]
+	userId := params["user_id"]
+	photoId := params["photo_id"]
+	commentId := params["comment_id"]
+
+	if authUserId != params["targeted_user_id"] {
+		rt.LoggerAndHttpErrorSender(w, errors.New("token differs from path user id"), utils.HttpError{StatusCode: http.StatusForbidden, Message: "You can't like a comment impersonating someone else"})
+		return
+	}
+
+	if !rt.canViewPhoto(w, photoId, userId, authUserId) {
+		return
+	}
+
+	isOperationSuccessful, dbErr := rt.db.LikeComment(authUserId, photoId, commentId)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	if !isOperationSuccessful {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: "Comment can't be liked"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = w.Write([]byte("Comment liked successfully"))
+}
+
+func (rt *_router) unlikeComment(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	authUserId := params["token"]
+	userId := params["user_id"]
+	photoId := params["photo_id"]
+	commentId := params["comment_id"]
+
+	if authUserId != params["targeted_user_id"] {
+		rt.LoggerAndHttpErrorSender(w, errors.New("who deletes like and authenticated user id are different"), utils.HttpError{StatusCode: http.StatusForbidden, Message: "You can't unlike a comment impersonating someone else"})
+		return
+	}
+
+	if !rt.db.DoesPhotoBelongToUser(userId, photoId) {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: utils.NotUserPhotoMessage})
+		return
+	}
+
+	isOperationSuccessful, dbErr := rt.db.UnlikeComment(authUserId, photoId, commentId)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	if !isOperationSuccessful {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusNotFound, Message: "Like not found"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = w.Write([]byte("Comment unliked successfully"))
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"wasaphoto/service/utils"
+)
+
+// getBanCommenterParam parses the ban query parameter, used by the photo owner to ban the author of the comment they
+// are moderating. It sends the error response, and returns false, if the parameter is malformed or who asks for the ban
+// is not the photo owner.
+func (rt *_router) getBanCommenterParam(w http.ResponseWriter, r *http.Request, userId int64, authUserId int64) (bool, bool) {
+	if !r.URL.Query().Has("ban") {
+		return false, true
+	}
+
+	ban, err := strconv.ParseBool(r.URL.Query().Get("ban"))
+	if err != nil {
+		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Query paramaters badly formatted"})
+		return false, false
+	}
+
+	if ban && userId != authUserId {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusForbidden, Message: "Only the photo owner can ban the author of a comment"})
+		return false, false
+	}
+
+	return ban, true
+}
+
+func (rt *_router) hideComment(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	rt.setCommentHidden(w, r, params, true)
+}
+
+func (rt *_router) unhideComment(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	rt.setCommentHidden(w, r, params, false)
+}
+
+// setCommentHidden hides, or shows again, a comment on a photo of the authenticated user
+func (rt *_router) setCommentHidden(w http.ResponseWriter, r *http.Request, params map[string]int64, hidden bool) {
+	authUserId := params["token"]
+	userId := params["user_id"]
+	photoId := params["photo_id"]
+	commentId := params["comment_id"]
+
+	if !rt.db.DoesPhotoBelongToUser(userId, photoId) {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: utils.NotUserPhotoMessage})
+		return
+	}
+
+	banCommenter, ok := rt.getBanCommenterParam(w, r, userId, authUserId)
+	if !ok {
+		return
+	}
+
+	isOperationSuccessful, dbErr := rt.db.HideComment(photoId, userId, commentId, hidden, banCommenter)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	if !isOperationSuccessful {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: "Comment does not belong to that photo"})
+		return
+	}
+
+	message := "Comment hidden successfully"
+	if !hidden {
+		message = "Comment shown successfully"
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = w.Write([]byte(message))
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"wasaphoto/service/database"
+	"wasaphoto/service/utils"
+)
+
+// canCommentPhoto returns true if the comment policy of the photo lets the authenticated user comment it. Otherwise,
+// a forbidden response explaining why is sent and false is returned.
+func (rt *_router) canCommentPhoto(w http.ResponseWriter, photoId int64, authUserId int64) bool {
+	access, dbErr := rt.db.GetCommentAccess(photoId, authUserId)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return false
+	}
+
+	switch access {
+	case database.CommentFollowersOnly:
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusForbidden, Message: utils.CommentsFollowersOnlyMessage})
+	case database.CommentFollowingOnly:
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusForbidden, Message: utils.CommentsFollowingOnlyMessage})
+	case database.CommentsDisabled:
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusForbidden, Message: utils.CommentsDisabledMessage})
+	}
+
+	return access == database.CommentAllowed
+}
+
+func (rt *_router) setPhotoCommentPolicy(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	photoId := params["photo_id"]
+	userId := params["user_id"]
+
+	var policy PhotoCommentPolicy
+	err := json.NewDecoder(r.Body).Decode(&policy)
+	if err != nil {
+		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Invalid request body"})
+		return
+	}
+
+	if !policy.IsValid() {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusBadRequest, Message: utils.InvalidCommentPolicyMessage})
+		return
+	}
+
+	isOperationSuccessful, dbErr := rt.db.SetPhotoCommentPolicy(photoId, userId, policy.CommentPolicy)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	if !isOperationSuccessful {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: utils.NotUserPhotoMessage})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(policy)
+}
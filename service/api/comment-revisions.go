@@ -0,0 +1,87 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"wasaphoto/service/globaltime"
+	"wasaphoto/service/utils"
+)
+
+func (rt *_router) editComment(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	authUserId := params["token"]
+	userId := params["user_id"]
+	photoId := params["photo_id"]
+	commentId := params["comment_id"]
+
+	var comment Comment
+	err := json.NewDecoder(r.Body).Decode(&comment)
+	if err != nil {
+		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Invalid request body"})
+		return
+	}
+
+	if strings.TrimSpace(comment.Content) == "" {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Comment cannot be empty"})
+		return
+	}
+
+	if !rt.canViewPhoto(w, photoId, userId, authUserId) {
+		return
+	}
+
+	// Only the comments posted within the edit window can be edited
+	editableSince := globaltime.Now().Add(-rt.commentEditWindow)
+	isOperationSuccessful, dbErr := rt.db.EditComment(photoId, authUserId, commentId, comment.Content, editableSince)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	if !isOperationSuccessful {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: "Comment does not belong to that user or can't be edited anymore"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = w.Write([]byte("Comment edited successfully"))
+}
+
+func (rt *_router) getCommentRevisions(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	authUserId := params["token"]
+	userId := params["user_id"]
+	photoId := params["photo_id"]
+	commentId := params["comment_id"]
+
+	if !rt.db.DoesPhotoBelongToUser(userId, photoId) {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: utils.NotUserPhotoMessage})
+		return
+	}
+
+	// The photo owner moderates the comments on their photos, nobody else can see the replaced versions
+	if authUserId != userId {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusForbidden, Message: "Only the photo owner can see the comment revisions"})
+		return
+	}
+
+	if !rt.db.DoesCommentBelongToPhoto(photoId, commentId) {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: "Comment does not belong to that photo"})
+		return
+	}
+
+	dbRevisions, dbErr := rt.db.GetCommentRevisions(commentId)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	revisions := CommentRevisions{Revisions: make([]CommentRevision, 0, len(dbRevisions))}
+	for _, dbRevision := range dbRevisions {
+		revisions.Revisions = append(revisions.Revisions, CommentRevision{Content: dbRevision.Content, WrittenAt: dbRevision.WrittenAt})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(revisions)
+}
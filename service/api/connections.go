@@ -0,0 +1,71 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+	"wasaphoto/service/database"
+	"wasaphoto/service/utils"
+)
+
+const (
+	// MaxMutualFollowsShown is the number of mutual follows listed, the others are only counted
+	MaxMutualFollowsShown = 10
+	// MaxConnectionDepth is the maximum number of follows of a connection path
+	MaxConnectionDepth = 6
+	// ConnectionSearchTimeout is the maximum time spent looking for a connection path
+	ConnectionSearchTimeout = 2 * time.Second
+)
+
+type Connections struct {
+	MutualFollows UsersPage `json:"mutualFollows"`
+	Degrees       *int      `json:"degrees"`
+	Path          []User    `json:"path"`
+	Complete      bool      `json:"searchComplete"`
+}
+
+func (rt *_router) getConnections(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	userId := params["user_id"]
+	otherId := params["other_id"]
+
+	isBanned, dbErr := rt.db.IsUserTargeted(otherId, userId, database.BanTable)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	} else if isBanned {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusForbidden, Message: utils.BannedMessage})
+		return
+	}
+
+	dbMutuals, dbErr := rt.db.GetMutualFollows(userId, otherId, MaxMutualFollowsShown)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	dbPath, isComplete, dbErr := rt.db.GetFollowPath(userId, otherId, MaxConnectionDepth, ConnectionSearchTimeout)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	var connections Connections
+	connections.MutualFollows.fromDatabase(dbMutuals)
+	connections.Complete = isComplete
+	connections.Path = make([]User, 0, len(dbPath))
+	for _, dbUser := range dbPath {
+		var user User
+		user.fromDatabase(dbUser)
+		connections.Path = append(connections.Path, user)
+	}
+
+	// The path includes both users, so its follows are one less than its users
+	if len(dbPath) > 0 {
+		degrees := len(dbPath) - 1
+		connections.Degrees = &degrees
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(connections)
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"wasaphoto/service/database"
+	"wasaphoto/service/utils"
+)
+
+// MaxImportRows is the maximum number of usernames of an import
+const MaxImportRows = 1000
+
+// Lists that can be exported and imported, with the table the imported users are added to
+var portableLists = map[string]string{
+	database.FollowingList: database.FollowTable,
+	database.BannedList:    database.BanTable,
+}
+
+type ExportedList struct {
+	List  string `json:"list"`
+	Users []User `json:"users"`
+}
+
+// ImportRequest accepts both a plain list of usernames and an exported list, whose users are imported by username
+type ImportRequest struct {
+	Usernames []string `json:"usernames"`
+	Users     []User   `json:"users"`
+}
+
+type ImportResult struct {
+	Row      int    `json:"row"`
+	Username string `json:"username"`
+	Id       int64  `json:"id,omitempty"`
+	Status   string `json:"status"`
+}
+
+type ImportReport struct {
+	Results []ImportResult `json:"results"`
+	Summary map[string]int `json:"summary"`
+}
+
+// getPortableList reads the list query parameter, returning false if it isn't a list that can be exported or imported
+func getPortableList(r *http.Request) (string, bool) {
+	list := r.URL.Query().Get("list")
+	_, isPortable := portableLists[list]
+	return list, isPortable
+}
+
+func (rt *_router) exportUsers(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	userId := params["user_id"]
+
+	list, isPortable := getPortableList(r)
+	format := r.URL.Query().Get("format")
+	if !isPortable || (format != "" && format != "json" && format != "csv") {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Query paramaters badly formatted"})
+		return
+	}
+
+	exported := ExportedList{List: list, Users: make([]User, 0)}
+	var cursor int64
+	for {
+		dbPage, dbErr := rt.db.GetUsersList(userId, list, cursor, MaxPageAmount)
+		if dbErr.InternalError != nil {
+			rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+			return
+		}
+
+		for _, dbUser := range dbPage.Users {
+			var user User
+			user.fromDatabase(dbUser)
+			exported.Users = append(exported.Users, user)
+		}
+
+		if dbPage.NextCursor == 0 {
+			break
+		}
+		cursor = dbPage.NextCursor
+	}
+
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", "attachment; filename=\""+list+".csv\"")
+		w.WriteHeader(http.StatusOK)
+		writer := csv.NewWriter(w)
+		_ = writer.Write([]string{"id", "username"})
+		for _, user := range exported.Users {
+			_ = writer.Write([]string{strconv.FormatInt(user.Id, 10), user.Username})
+		}
+		writer.Flush()
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+list+".json\"")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(exported)
+}
+
+// readCsvUsernames reads the usernames of a CSV import: the username column if the first row is a header naming it,
+// the first column otherwise
+func readCsvUsernames(body io.Reader) ([]string, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	column := 0
+	if len(records) > 0 {
+		for i, field := range records[0] {
+			if strings.EqualFold(strings.TrimSpace(field), "username") {
+				column = i
+				records = records[1:]
+				break
+			}
+		}
+	}
+
+	var usernames []string
+	for _, record := range records {
+		if column < len(record) {
+			usernames = append(usernames, record[column])
+		}
+	}
+
+	return usernames, nil
+}
+
+func (rt *_router) importUsers(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	userId := params["user_id"]
+
+	list, isPortable := getPortableList(r)
+	if !isPortable {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Query paramaters badly formatted"})
+		return
+	}
+
+	var usernames []string
+	var err error
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "text/csv" {
+		usernames, err = readCsvUsernames(r.Body)
+	} else {
+		var request ImportRequest
+		err = json.NewDecoder(r.Body).Decode(&request)
+		usernames = request.Usernames
+		for _, user := range request.Users {
+			usernames = append(usernames, user.Username)
+		}
+	}
+	if err != nil {
+		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Invalid request body"})
+		return
+	}
+
+	// Blank rows are skipped, the row numbers of the report count only the imported usernames
+	var rows []string
+	for _, username := range usernames {
+		username = strings.TrimSpace(username)
+		if username != "" {
+			rows = append(rows, username)
+		}
+	}
+
+	if len(rows) == 0 || len(rows) > MaxImportRows {
+		rt.LoggerAndHttpErrorSender(w, errors.New("invalid import size"), utils.HttpError{StatusCode: http.StatusBadRequest, Message: "An import has to contain from 1 to " + strconv.Itoa(MaxImportRows) + " usernames"})
+		return
+	}
+
+	dbResults, dbErr := rt.db.ImportUsers(userId, portableLists[list], rows)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	report := ImportReport{Results: make([]ImportResult, 0, len(dbResults)), Summary: make(map[string]int)}
+	for i, dbResult := range dbResults {
+		report.Results = append(report.Results, ImportResult{Row: i + 1, Username: dbResult.Username, Id: dbResult.UserId,
+			Status: dbResult.Status})
+		report.Summary[dbResult.Status]++
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(report)
+}
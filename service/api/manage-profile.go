@@ -4,7 +4,8 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
-	"strconv"
+	"strings"
+	"time"
 	"wasaphoto/service/database"
 	"wasaphoto/service/utils"
 )
@@ -18,34 +19,92 @@ func (rt *_router) uploadPhoto(w http.ResponseWriter, r *http.Request, params ma
 		return
 	}
 
-	dbErr := rt.db.InsertPhoto(photo, userId)
+	altText := AltText{AltText: strings.TrimSpace(r.URL.Query().Get("altText"))}
+	if !altText.IsValid() {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Alt text is too long"})
+		return
+	}
+
+	// Photos with a publish time are scheduled, the others are published immediately
+	var publishAt time.Time
+	if r.URL.Query().Has("publishAt") {
+		publishAt, err = parsePublishTime(r.URL.Query().Get("publishAt"))
+		if err != nil {
+			rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Publish time has to be a future RFC 3339 timestamp"})
+			return
+		}
+	}
+
+	visibility := PhotoVisibility{Visibility: database.PublicVisibility}
+	if r.URL.Query().Has("visibility") {
+		visibility.Visibility = r.URL.Query().Get("visibility")
+		if !visibility.IsValid() {
+			rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusBadRequest, Message: utils.InvalidVisibilityMessage})
+			return
+		}
+	}
+
+	var uploadedPhoto UploadedPhoto
+	var dbErr database.DbError
+	uploadedPhoto.Id, dbErr = rt.db.InsertPhoto(photo, userId, altText.AltText, publishAt, visibility.Visibility)
 	if dbErr.InternalError != nil {
-		rt.LoggerAndHttpErrorSender(w, err, dbErr.ToHttp())
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
 		return
 	}
 
+	if altText.AltText == "" {
+		uploadedPhoto.Warnings = append(uploadedPhoto.Warnings, utils.MissingAltTextWarning)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	w.Header().Set("Content-Type", "text/plain")
-	_, _ = w.Write([]byte("Photo uploaded successfully"))
+	_ = json.NewEncoder(w).Encode(uploadedPhoto)
 }
 
-func (rt *_router) getImage(w http.ResponseWriter, r *http.Request, params map[string]int64) {
-
+func (rt *_router) setPhotoAltText(w http.ResponseWriter, r *http.Request, params map[string]int64) {
 	photoId := params["photo_id"]
-	authUserId := params["token"]
 	userId := params["user_id"]
 
-	userIsBanned, dbErr := rt.db.IsUserTargeted(userId, authUserId, database.BanTable)
+	var altText AltText
+	err := json.NewDecoder(r.Body).Decode(&altText)
+	if err != nil {
+		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Invalid request body"})
+		return
+	}
+
+	altText.AltText = strings.TrimSpace(altText.AltText)
+	if !altText.IsValid() {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Alt text is too long"})
+		return
+	}
+
+	isOperationSuccessful, dbErr := rt.db.SetPhotoAltText(photoId, userId, altText.AltText)
 	if dbErr.InternalError != nil {
 		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
 		return
-	} else if userIsBanned {
-		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusForbidden, Message: utils.BannedMessage})
+	}
+
+	if !isOperationSuccessful {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: "Photo does not belong to that user"})
 		return
 	}
 
-	var image []byte
-	image, dbErr = rt.db.GetImage(photoId, userId)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(altText)
+}
+
+func (rt *_router) getImage(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+
+	photoId := params["photo_id"]
+	authUserId := params["token"]
+	userId := params["user_id"]
+
+	if !rt.canViewPhoto(w, photoId, userId, authUserId) {
+		return
+	}
+
+	image, dbErr := rt.db.GetImage(photoId, userId, authUserId)
 	if dbErr.InternalError != nil {
 		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
 		return
@@ -119,13 +178,7 @@ func (rt *_router) getUserProfile(w http.ResponseWriter, r *http.Request, params
 
 	var userProfile UserProfile
 	// get query params
-	offset, err := strconv.ParseInt(r.URL.Query().Get("offset"), 10, 64)
-	if err != nil {
-		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Query paramaters badly formatted"})
-		return
-	}
-
-	amount, err := strconv.ParseInt(r.URL.Query().Get("amount"), 10, 64)
+	offset, amount, err := getPaginationParams(r)
 	if err != nil {
 		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Query paramaters badly formatted"})
 		return
@@ -144,13 +197,25 @@ func (rt *_router) getUserProfile(w http.ResponseWriter, r *http.Request, params
 		return
 	}
 
-	up, dbErr := rt.db.GetUserProfile(userId, amount, offset)
+	// Only the photos the authenticated user can see are listed and counted: private accounts show just their name and
+	// follow counters to who is not an approved follower
+	up, dbErr := rt.db.GetUserProfile(userId, authUserId, amount, offset)
 	if dbErr.InternalError != nil {
 		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
 		return
 	}
 	userProfile.fromDatabase(up)
 
+	relationships, dbErr := rt.db.GetRelationships(authUserId, []int64{userId})
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	if len(relationships) > 0 {
+		userProfile.Relationship.fromDatabase(relationships[0])
+	}
+
 	w.WriteHeader(http.StatusOK)
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(userProfile)
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// getPaginationParams parses the offset and amount query parameters of the request
+func getPaginationParams(r *http.Request) (int64, int64, error) {
+	offset, err := strconv.ParseInt(r.URL.Query().Get("offset"), 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	amount, err := strconv.ParseInt(r.URL.Query().Get("amount"), 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if offset < 0 || amount < 0 {
+		return 0, 0, errors.New("offset and amount can't be negative")
+	}
+
+	return offset, amount, nil
+}
+
+const (
+	// DefaultPageAmount is the number of items of a cursor paginated page when the amount is not given
+	DefaultPageAmount = 50
+
+	// MaxPageAmount is the maximum number of items of a cursor paginated page
+	MaxPageAmount = 100
+)
+
+// getCursorParams parses the optional cursor and amount query parameters of the request. The cursor defaults to 0,
+// the first page, and the amount to DefaultPageAmount.
+func getCursorParams(r *http.Request) (int64, int64, error) {
+	var cursor int64
+	var amount int64 = DefaultPageAmount
+	var err error
+
+	if r.URL.Query().Has("cursor") {
+		cursor, err = strconv.ParseInt(r.URL.Query().Get("cursor"), 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if r.URL.Query().Has("amount") {
+		amount, err = strconv.ParseInt(r.URL.Query().Get("amount"), 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if cursor < 0 || amount < 1 || amount > MaxPageAmount {
+		return 0, 0, errors.New("cursor or amount out of range")
+	}
+
+	return cursor, amount, nil
+}
@@ -18,25 +18,11 @@ func (rt *_router) likePhoto(w http.ResponseWriter, r *http.Request, params map[
 		return
 	}
 
-	// Check if the user who wants to like photo is banned by photo owner
-	isBanned, dbErr := rt.db.IsUserTargeted(userId, authUserId, database.BanTable)
-	if dbErr.InternalError != nil {
-		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
-		return
-	}
-
-	if isBanned {
-		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusForbidden, Message: utils.BannedMessage})
-		return
-	}
-
-	if !rt.db.DoesPhotoBelongToUser(userId, photoId) {
-		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: utils.NotUserPhotoMessage})
+	if !rt.canViewPhoto(w, photoId, userId, authUserId) {
 		return
 	}
 
-	var isOperationSuccessful bool
-	isOperationSuccessful, dbErr = rt.db.LikePhoto(authUserId, photoId, userId)
+	isOperationSuccessful, dbErr := rt.db.LikePhoto(authUserId, photoId, userId)
 	if dbErr.InternalError != nil {
 		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
 		return
@@ -99,18 +85,7 @@ func (rt *_router) commentPhoto(w http.ResponseWriter, r *http.Request, params m
 	userId := params["user_id"]
 	photoId := params["photo_id"]
 
-	// Check if the user who wants to like photo is banned by photo owner
-	isBanned, dbErr := rt.db.IsUserTargeted(userId, authUserId, database.BanTable)
-	if dbErr.InternalError != nil {
-		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
-		return
-	} else if isBanned {
-		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusForbidden, Message: utils.BannedMessage})
-		return
-	}
-
-	if !rt.db.DoesPhotoBelongToUser(userId, photoId) {
-		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: utils.NotUserPhotoMessage})
+	if !rt.canViewPhoto(w, photoId, userId, authUserId) || !rt.canCommentPhoto(w, photoId, authUserId) {
 		return
 	}
 
@@ -122,15 +97,14 @@ func (rt *_router) commentPhoto(w http.ResponseWriter, r *http.Request, params m
 	}
 
 	// var if operation is not successful, it will be nil
-	var isOperationSuccessful bool
-	isOperationSuccessful, dbErr = rt.db.CommentPhoto(authUserId, photoId, userId, comment.Content)
+	isOperationSuccessful, dbErr := rt.db.CommentPhoto(authUserId, photoId, userId, comment.Content, comment.ParentId)
 	if dbErr.InternalError != nil {
 		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
 		return
 	}
 
 	if !isOperationSuccessful {
-		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: "Comment does not belong to that user"})
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: utils.CannotReplyMessage})
 		return
 	}
 
@@ -143,22 +117,20 @@ func (rt *_router) getPhotoComments(w http.ResponseWriter, r *http.Request, para
 	userId := params["user_id"]
 	photoId := params["photo_id"]
 
-	isBanned, dbErr := rt.db.IsUserTargeted(userId, authUserId, database.BanTable)
-	if dbErr.InternalError != nil {
-		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
-		return
-	} else if isBanned {
-		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusForbidden, Message: utils.BannedMessage})
+	if !rt.canViewPhoto(w, photoId, userId, authUserId) {
 		return
 	}
 
-	if !rt.db.DoesPhotoBelongToUser(userId, photoId) {
-		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: utils.NotUserPhotoMessage})
+	order := r.URL.Query().Get("sort")
+	if order == "" {
+		order = database.CommentsOrderRecent
+	} else if order != database.CommentsOrderRecent && order != database.CommentsOrderTop {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Sort has to be recent or top"})
 		return
 	}
 
 	var commentsObject CommentsObject
-	dbComments, dbErr := rt.db.GetPhotoComments(photoId, userId)
+	dbComments, dbErr := rt.db.GetPhotoComments(photoId, userId, authUserId, order)
 	if dbErr.InternalError != nil {
 		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
 		return
@@ -179,6 +151,46 @@ func (rt *_router) getPhotoComments(w http.ResponseWriter, r *http.Request, para
 	_ = json.NewEncoder(w).Encode(commentsObject)
 }
 
+func (rt *_router) getCommentReplies(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	authUserId := params["token"]
+	userId := params["user_id"]
+	photoId := params["photo_id"]
+	commentId := params["comment_id"]
+
+	offset, amount, err := getPaginationParams(r)
+	if err != nil {
+		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Query paramaters badly formatted"})
+		return
+	}
+
+	if !rt.canViewPhoto(w, photoId, userId, authUserId) {
+		return
+	}
+
+	if !rt.db.DoesCommentBelongToPhoto(photoId, commentId) {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: "Comment does not belong to that photo"})
+		return
+	}
+
+	var commentsObject CommentsObject
+	dbComments, dbErr := rt.db.GetCommentReplies(commentId, userId, authUserId, amount, offset)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	commentsObject.Comments = make([]Comment, 0, len(dbComments))
+	for _, dbComment := range dbComments {
+		var comment Comment
+		comment.fromDatabase(dbComment)
+		commentsObject.Comments = append(commentsObject.Comments, comment)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(commentsObject)
+}
+
 func (rt *_router) deleteComment(w http.ResponseWriter, r *http.Request, params map[string]int64) {
 	commentId := params["comment_id"]
 	userId := params["user_id"]
@@ -190,8 +202,14 @@ func (rt *_router) deleteComment(w http.ResponseWriter, r *http.Request, params
 		return
 	}
 
+	// The photo owner can delete any comment on their photos, and ban its author at the same time
+	banCommenter, ok := rt.getBanCommenterParam(w, r, userId, authUserId)
+	if !ok {
+		return
+	}
+
 	var isOperationSuccessful bool
-	isOperationSuccessful, dbErr := rt.db.DeleteComment(photoId, userId, authUserId, commentId)
+	isOperationSuccessful, dbErr := rt.db.DeleteComment(photoId, authUserId, commentId, banCommenter)
 	if dbErr.InternalError != nil {
 		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
 		return
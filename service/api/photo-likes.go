@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"wasaphoto/service/database"
+	"wasaphoto/service/utils"
+)
+
+type Liker struct {
+	User
+	Followed bool   `json:"followed"`
+	Reaction string `json:"reaction"`
+}
+
+type LikesPage struct {
+	Likers []Liker `json:"likers"`
+	Total  int     `json:"total"`
+}
+
+func (p *LikesPage) fromDatabase(dbPage database.LikesPage) {
+	p.Likers = make([]Liker, 0, len(dbPage.Likers))
+	for _, dbLiker := range dbPage.Likers {
+		var liker Liker
+		liker.User.fromDatabase(dbLiker.User)
+		liker.Followed = dbLiker.Followed
+		liker.Reaction = dbLiker.Reaction
+		p.Likers = append(p.Likers, liker)
+	}
+	p.Total = dbPage.Total
+}
+
+func (rt *_router) getPhotoLikes(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	authUserId := params["token"]
+	userId := params["user_id"]
+	photoId := params["photo_id"]
+
+	offset, amount, err := getPaginationParams(r)
+	if err != nil {
+		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Query paramaters badly formatted"})
+		return
+	}
+
+	if !rt.canViewPhoto(w, photoId, userId, authUserId) {
+		return
+	}
+
+	// Likes are the reactions of any kind, unless one is asked for
+	reaction := r.URL.Query().Get("reaction")
+	if reaction != "" && !database.IsReaction(reaction) {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusBadRequest, Message: utils.InvalidReactionMessage})
+		return
+	}
+
+	dbPage, dbErr := rt.db.GetPhotoLikes(photoId, authUserId, reaction, amount, offset)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	var page LikesPage
+	page.fromDatabase(dbPage)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(page)
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"wasaphoto/service/database"
+	"wasaphoto/service/utils"
+)
+
+type Reaction struct {
+	Reaction string `json:"reaction"`
+}
+
+type ReactionsPage struct {
+	ReactionsCounters map[string]int `json:"reactionsCounters"`
+	LikesPage
+}
+
+func (rt *_router) reactToPhoto(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	authUserId := params["token"]
+	userId := params["user_id"]
+	photoId := params["photo_id"]
+
+	if authUserId != params["targeted_user_id"] {
+		rt.LoggerAndHttpErrorSender(w, errors.New("token differs from path user id"), utils.HttpError{StatusCode: http.StatusForbidden, Message: "You can't react to a photo impersonating someone else"})
+		return
+	}
+
+	var reaction Reaction
+	err := json.NewDecoder(r.Body).Decode(&reaction)
+	if err != nil {
+		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Invalid request body"})
+		return
+	}
+
+	if !database.IsReaction(reaction.Reaction) {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusBadRequest, Message: utils.InvalidReactionMessage})
+		return
+	}
+
+	if !rt.canViewPhoto(w, photoId, userId, authUserId) {
+		return
+	}
+
+	_, dbErr := rt.db.ReactToPhoto(authUserId, photoId, reaction.Reaction)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(reaction)
+}
+
+func (rt *_router) getPhotoReactions(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	authUserId := params["token"]
+	userId := params["user_id"]
+	photoId := params["photo_id"]
+
+	offset, amount, err := getPaginationParams(r)
+	if err != nil {
+		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Query paramaters badly formatted"})
+		return
+	}
+
+	reaction := r.URL.Query().Get("reaction")
+	if reaction != "" && !database.IsReaction(reaction) {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusBadRequest, Message: utils.InvalidReactionMessage})
+		return
+	}
+
+	if !rt.canViewPhoto(w, photoId, userId, authUserId) {
+		return
+	}
+
+	var page ReactionsPage
+	var dbErr database.DbError
+	page.ReactionsCounters, dbErr = rt.db.GetReactionsCounters(photoId, authUserId)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	dbPage, dbErr := rt.db.GetPhotoLikes(photoId, authUserId, reaction, amount, offset)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+	page.LikesPage.fromDatabase(dbPage)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(page)
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"wasaphoto/service/database"
+	"wasaphoto/service/utils"
+)
+
+// canViewPhoto returns true if the authenticated user can see the photo of the user in path. Otherwise, an error
+// response explaining why is sent and false is returned. Every handler reading or interacting with a single photo
+// checks it here.
+func (rt *_router) canViewPhoto(w http.ResponseWriter, photoId int64, userId int64, authUserId int64) bool {
+	access, dbErr := rt.db.GetPhotoAccess(photoId, userId, authUserId)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return false
+	}
+
+	switch access {
+	case database.PhotoNotOwned:
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: utils.NotUserPhotoMessage})
+	case database.PhotoOwnerBanned:
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusForbidden, Message: utils.BannedMessage})
+	case database.PhotoAccountPrivate:
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusForbidden, Message: utils.PrivateAccountMessage})
+	case database.PhotoRestricted:
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusForbidden, Message: utils.RestrictedPhotoMessage})
+	}
+
+	return access == database.PhotoAccessGranted
+}
+
+func (rt *_router) setPhotoVisibility(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	photoId := params["photo_id"]
+	userId := params["user_id"]
+
+	var visibility PhotoVisibility
+	err := json.NewDecoder(r.Body).Decode(&visibility)
+	if err != nil {
+		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Invalid request body"})
+		return
+	}
+
+	if !visibility.IsValid() {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusBadRequest, Message: utils.InvalidVisibilityMessage})
+		return
+	}
+
+	isOperationSuccessful, dbErr := rt.db.SetPhotoVisibility(photoId, userId, visibility.Visibility)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	if !isOperationSuccessful {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: utils.NotUserPhotoMessage})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(visibility)
+}
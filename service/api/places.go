@@ -0,0 +1,228 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+	"wasaphoto/service/database"
+	"wasaphoto/service/geo"
+	"wasaphoto/service/utils"
+)
+
+const (
+	// MaxPlaceNameLength is the maximum number of characters of a place name
+	MaxPlaceNameLength = 100
+	// MaxNearbyRadius is the maximum radius, in meters, of a nearby photos search
+	MaxNearbyRadius = 50000
+	// DefaultNearbyAmount is the amount of photos returned by a nearby search when the amount is not given
+	DefaultNearbyAmount = 20
+	// MaxNearbyAmount is the maximum amount of photos returned by a nearby search
+	MaxNearbyAmount = 100
+)
+
+type Place struct {
+	Name      string  `json:"name"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+func (p Place) IsValid() bool {
+	nameLength := utf8.RuneCountInString(p.Name)
+	return nameLength > 0 && nameLength <= MaxPlaceNameLength && geo.IsValidPoint(p.Latitude, p.Longitude)
+}
+
+func (p *Place) fromDatabase(dbPlace database.Place) {
+	p.Name = dbPlace.Name
+	p.Latitude = dbPlace.Latitude
+	p.Longitude = dbPlace.Longitude
+}
+
+func (p Place) toDatabase() database.Place {
+	return database.Place{
+		Name:      p.Name,
+		Latitude:  p.Latitude,
+		Longitude: p.Longitude,
+	}
+}
+
+type PlaceCluster struct {
+	Geohash    string  `json:"geohash"`
+	Latitude   float64 `json:"latitude"`
+	Longitude  float64 `json:"longitude"`
+	Count      int     `json:"count"`
+	CoverId    int64   `json:"coverId"`
+	CoverOwner User    `json:"coverOwner"`
+}
+
+func (c *PlaceCluster) fromDatabase(dbCluster database.PlaceCluster) {
+	c.Geohash = dbCluster.Geohash
+	c.Latitude = dbCluster.Latitude
+	c.Longitude = dbCluster.Longitude
+	c.Count = dbCluster.Count
+	c.CoverId = dbCluster.CoverId
+	c.CoverOwner.fromDatabase(dbCluster.CoverOwner)
+}
+
+type PlaceClusters struct {
+	Clusters []PlaceCluster `json:"clusters"`
+}
+
+// getFloatQueryParam parses the query parameter with the given name as a float
+func getFloatQueryParam(r *http.Request, name string) (float64, error) {
+	value, err := strconv.ParseFloat(r.URL.Query().Get(name), 64)
+	if err == nil && (math.IsNaN(value) || math.IsInf(value, 0)) {
+		err = errors.New("query parameter " + name + " is not a number")
+	}
+	return value, err
+}
+
+func (rt *_router) setPhotoPlace(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	photoId := params["photo_id"]
+	userId := params["user_id"]
+
+	var place Place
+	err := json.NewDecoder(r.Body).Decode(&place)
+	if err != nil {
+		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Invalid request body"})
+		return
+	}
+
+	place.Name = strings.TrimSpace(place.Name)
+	if !place.IsValid() {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Invalid place"})
+		return
+	}
+
+	isOperationSuccessful, dbErr := rt.db.SetPhotoPlace(photoId, userId, place.toDatabase())
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	if !isOperationSuccessful {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: "Photo does not belong to that user"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(place)
+}
+
+func (rt *_router) removePhotoPlace(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	photoId := params["photo_id"]
+	userId := params["user_id"]
+
+	isOperationSuccessful, dbErr := rt.db.RemovePhotoPlace(photoId, userId)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	if !isOperationSuccessful {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: "Photo does not belong to that user or has no place"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("Place removed successfully"))
+}
+
+func (rt *_router) getNearbyPhotos(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	authUserId := params["token"]
+
+	lat, err := getFloatQueryParam(r, "lat")
+	if err != nil {
+		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Query paramaters badly formatted"})
+		return
+	}
+
+	lon, err := getFloatQueryParam(r, "lon")
+	if err != nil {
+		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Query paramaters badly formatted"})
+		return
+	}
+
+	radius, err := getFloatQueryParam(r, "radius")
+	if err != nil {
+		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Query paramaters badly formatted"})
+		return
+	}
+
+	if !geo.IsValidPoint(lat, lon) || radius <= 0 || radius > MaxNearbyRadius {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Coordinates or radius out of range"})
+		return
+	}
+
+	var amount int64 = DefaultNearbyAmount
+	if r.URL.Query().Has("amount") {
+		amount, err = strconv.ParseInt(r.URL.Query().Get("amount"), 10, 64)
+		if err != nil || amount <= 0 || amount > MaxNearbyAmount {
+			rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Query paramaters badly formatted"})
+			return
+		}
+	}
+
+	dbPhotos, dbErr := rt.db.GetNearbyPhotos(authUserId, lat, lon, radius, amount)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	photos := make([]Photo, 0, len(dbPhotos))
+	for _, dbPhoto := range dbPhotos {
+		var photo Photo
+		photo.fromDatabase(dbPhoto)
+		photos = append(photos, photo)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(UserStream{photos})
+}
+
+func (rt *_router) getPlaceClusters(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	authUserId := params["token"]
+
+	var box geo.Box
+	var err error
+	for name, value := range map[string]*float64{
+		"minLat": &box.MinLat,
+		"minLon": &box.MinLon,
+		"maxLat": &box.MaxLat,
+		"maxLon": &box.MaxLon,
+	} {
+		*value, err = getFloatQueryParam(r, name)
+		if err != nil {
+			rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Query paramaters badly formatted"})
+			return
+		}
+	}
+
+	if !box.IsValid() {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Invalid bounding box"})
+		return
+	}
+
+	dbClusters, dbErr := rt.db.GetPlaceClusters(authUserId, box)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	clusters := PlaceClusters{Clusters: make([]PlaceCluster, 0, len(dbClusters))}
+	for _, dbCluster := range dbClusters {
+		var cluster PlaceCluster
+		cluster.fromDatabase(dbCluster)
+		clusters.Clusters = append(clusters.Clusters, cluster)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(clusters)
+}
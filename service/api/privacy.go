@@ -0,0 +1,110 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"wasaphoto/service/utils"
+)
+
+type AccountPrivacy struct {
+	Private bool `json:"private"`
+}
+
+// isContentVisible returns true if the authenticated user can see the photos of the user. Otherwise, an error response
+// is sent and false is returned.
+func (rt *_router) isContentVisible(w http.ResponseWriter, authUserId int64, userId int64) bool {
+	canSee, dbErr := rt.db.CanSeeUserContent(authUserId, userId)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return false
+	}
+
+	if !canSee {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusForbidden, Message: utils.PrivateAccountMessage})
+		return false
+	}
+
+	return true
+}
+
+func (rt *_router) setAccountPrivacy(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	userId := params["user_id"]
+
+	var privacy AccountPrivacy
+	err := json.NewDecoder(r.Body).Decode(&privacy)
+	if err != nil {
+		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Invalid request body"})
+		return
+	}
+
+	dbErr := rt.db.SetUserPrivate(userId, privacy.Private)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(privacy)
+}
+
+func (rt *_router) getFollowRequests(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	userId := params["user_id"]
+
+	dbUsers, dbErr := rt.db.GetFollowRequests(userId)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	users := make([]User, 0, len(dbUsers))
+	for _, dbUser := range dbUsers {
+		var user User
+		user.fromDatabase(dbUser)
+		users = append(users, user)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(userList{users})
+}
+
+func (rt *_router) approveFollowRequest(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	userId := params["user_id"]
+	requesterId := params["targeted_user_id"]
+
+	isOperationSuccessful, dbErr := rt.db.ApproveFollowRequest(userId, requesterId)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	if !isOperationSuccessful {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusNotFound, Message: "Follow request not found"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("Follow request approved successfully"))
+}
+
+func (rt *_router) rejectFollowRequest(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	userId := params["user_id"]
+	requesterId := params["targeted_user_id"]
+
+	isOperationSuccessful, dbErr := rt.db.DeleteFollowRequest(userId, requesterId)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	if !isOperationSuccessful {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusNotFound, Message: "Follow request not found"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("Follow request rejected successfully"))
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"wasaphoto/service/database"
+	"wasaphoto/service/utils"
+)
+
+// MaxRelationshipsIds is the maximum number of users whose relationships can be asked at once
+const MaxRelationshipsIds = 100
+
+// Relationship describes how the authenticated user is related to another user
+type Relationship struct {
+	UserId     int64 `json:"userId"`
+	Following  bool  `json:"following"`
+	FollowedBy bool  `json:"followedBy"`
+	Banned     bool  `json:"banned"`
+	Muted      bool  `json:"muted"`
+	Requested  bool  `json:"requested"`
+}
+
+func (rel *Relationship) fromDatabase(dbRelationship database.Relationship) {
+	rel.UserId = dbRelationship.UserId
+	rel.Following = dbRelationship.Following
+	rel.FollowedBy = dbRelationship.FollowedBy
+	rel.Banned = dbRelationship.Banned
+	rel.Muted = dbRelationship.Muted
+	rel.Requested = dbRelationship.Requested
+}
+
+type Relationships struct {
+	Relationships []Relationship `json:"relationships"`
+}
+
+// parseIds parses a comma separated list of ids
+func parseIds(value string) ([]int64, error) {
+	var ids []int64
+
+	for _, field := range strings.Split(value, ",") {
+		id, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if len(ids) > MaxRelationshipsIds {
+		return nil, errors.New("too many ids")
+	}
+
+	return ids, nil
+}
+
+func (rt *_router) getRelationships(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	authUserId := params["token"]
+
+	ids, err := parseIds(r.URL.Query().Get("ids"))
+	if err != nil {
+		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Query paramaters badly formatted"})
+		return
+	}
+
+	dbRelationships, dbErr := rt.db.GetRelationships(authUserId, ids)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	relationships := make([]Relationship, 0, len(dbRelationships))
+	for _, dbRelationship := range dbRelationships {
+		var relationship Relationship
+		relationship.fromDatabase(dbRelationship)
+		relationships = append(relationships, relationship)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(Relationships{relationships})
+}
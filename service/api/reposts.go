@@ -0,0 +1,68 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"wasaphoto/service/utils"
+)
+
+func (rt *_router) repostPhoto(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	authUserId := params["token"]
+	userId := params["user_id"]
+	photoId := params["photo_id"]
+
+	if authUserId != params["targeted_user_id"] {
+		rt.LoggerAndHttpErrorSender(w, errors.New("token differs from path user id"), utils.HttpError{StatusCode: http.StatusForbidden, Message: "You can't repost a photo impersonating someone else"})
+		return
+	}
+
+	if !rt.canViewPhoto(w, photoId, userId, authUserId) {
+		return
+	}
+
+	if userId == authUserId {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: "You can't repost your own photo"})
+		return
+	}
+
+	_, dbErr := rt.db.RepostPhoto(authUserId, photoId)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = w.Write([]byte("Photo reposted successfully"))
+}
+
+func (rt *_router) unrepostPhoto(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	authUserId := params["token"]
+	userId := params["user_id"]
+	photoId := params["photo_id"]
+
+	if authUserId != params["targeted_user_id"] {
+		rt.LoggerAndHttpErrorSender(w, errors.New("who deletes repost and authenticated user id are different"), utils.HttpError{StatusCode: http.StatusForbidden, Message: "You can't undo a repost impersonating someone else"})
+		return
+	}
+
+	if !rt.db.DoesPhotoBelongToUser(userId, photoId) {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: utils.NotUserPhotoMessage})
+		return
+	}
+
+	isOperationSuccessful, dbErr := rt.db.UnrepostPhoto(authUserId, photoId)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	if !isOperationSuccessful {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusNotFound, Message: "Repost not found"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = w.Write([]byte("Photo unreposted successfully"))
+}
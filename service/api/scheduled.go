@@ -0,0 +1,108 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+	"wasaphoto/service/globaltime"
+	"wasaphoto/service/utils"
+)
+
+type PublishTime struct {
+	PublishAt string `json:"publishAt"`
+}
+
+// parsePublishTime parses an RFC 3339 timestamp, which has to be in the future to schedule a photo
+func parsePublishTime(value string) (time.Time, error) {
+	publishAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return publishAt, err
+	}
+
+	if !publishAt.After(globaltime.Now()) {
+		return publishAt, errors.New("publish time is not in the future")
+	}
+
+	return publishAt, nil
+}
+
+func (rt *_router) getScheduledPhotos(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	userId := params["user_id"]
+
+	offset, amount, err := getPaginationParams(r)
+	if err != nil {
+		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Query paramaters badly formatted"})
+		return
+	}
+
+	dbPhotos, dbErr := rt.db.GetScheduledPhotos(userId, amount, offset)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	photos := make([]Photo, 0, len(dbPhotos))
+	for _, dbPhoto := range dbPhotos {
+		var photo Photo
+		photo.fromDatabase(dbPhoto)
+		photos = append(photos, photo)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(UserStream{photos})
+}
+
+func (rt *_router) reschedulePhoto(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	photoId := params["photo_id"]
+	userId := params["user_id"]
+
+	var publishTime PublishTime
+	err := json.NewDecoder(r.Body).Decode(&publishTime)
+	if err != nil {
+		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Invalid request body"})
+		return
+	}
+
+	publishAt, err := parsePublishTime(publishTime.PublishAt)
+	if err != nil {
+		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Publish time has to be a future RFC 3339 timestamp"})
+		return
+	}
+
+	isOperationSuccessful, dbErr := rt.db.ReschedulePhoto(photoId, userId, publishAt)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	if !isOperationSuccessful {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: "Photo not scheduled by that user"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(PublishTime{PublishAt: publishAt.UTC().Format(time.RFC3339)})
+}
+
+func (rt *_router) cancelScheduledPhoto(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	photoId := params["photo_id"]
+	userId := params["user_id"]
+
+	isOperationSuccessful, dbErr := rt.db.CancelScheduledPhoto(photoId, userId)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	if !isOperationSuccessful {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: "Photo not scheduled by that user"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("Scheduled photo canceled successfully"))
+}
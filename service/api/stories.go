@@ -0,0 +1,199 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"wasaphoto/service/database"
+	"wasaphoto/service/utils"
+)
+
+type Story struct {
+	Id        int64  `json:"id"`
+	Owner     User   `json:"owner"`
+	CreatedAt string `json:"createdAt"`
+	ExpiresAt string `json:"expiresAt"`
+	Seen      bool   `json:"seen"`
+}
+
+func (s *Story) fromDatabase(dbStory database.Story) {
+	s.Id = dbStory.Id
+	s.Owner.fromDatabase(dbStory.Owner)
+	s.CreatedAt = dbStory.CreatedAt
+	s.ExpiresAt = dbStory.ExpiresAt
+	s.Seen = dbStory.Seen
+}
+
+type PostedStory struct {
+	Id        int64  `json:"id"`
+	CreatedAt string `json:"createdAt"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+type StoryAuthor struct {
+	User    User    `json:"user"`
+	AllSeen bool    `json:"allSeen"`
+	Stories []Story `json:"stories"`
+}
+
+func (a *StoryAuthor) fromDatabase(dbAuthor database.StoryAuthor) {
+	a.User.fromDatabase(dbAuthor.User)
+	a.AllSeen = dbAuthor.AllSeen
+	a.Stories = make([]Story, 0, len(dbAuthor.Stories))
+	for _, dbStory := range dbAuthor.Stories {
+		var story Story
+		story.fromDatabase(dbStory)
+		a.Stories = append(a.Stories, story)
+	}
+}
+
+type StoriesTray struct {
+	Authors []StoryAuthor `json:"authors"`
+}
+
+type StoryViewer struct {
+	User   User   `json:"user"`
+	SeenAt string `json:"seenAt"`
+}
+
+type StoryViewers struct {
+	Viewers []StoryViewer `json:"viewers"`
+}
+
+func (rt *_router) postStory(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	userId := params["user_id"]
+
+	image, err := io.ReadAll(r.Body)
+	if err != nil || len(image) == 0 {
+		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	dbStory, dbErr := rt.db.InsertStory(image, userId)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(PostedStory{Id: dbStory.Id, CreatedAt: dbStory.CreatedAt, ExpiresAt: dbStory.ExpiresAt})
+}
+
+// getStoryImage returns the story image to its author and to their followers, and marks it as seen by the latter
+func (rt *_router) getStoryImage(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	storyId := params["story_id"]
+	authUserId := params["token"]
+	userId := params["user_id"]
+
+	if authUserId != userId {
+		userIsBanned, dbErr := rt.db.IsUserTargeted(userId, authUserId, database.BanTable)
+		if dbErr.InternalError != nil {
+			rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+			return
+		} else if userIsBanned {
+			rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusForbidden, Message: utils.BannedMessage})
+			return
+		}
+
+		isFollower, dbErr := rt.db.IsUserTargeted(authUserId, userId, database.FollowTable)
+		if dbErr.InternalError != nil {
+			rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+			return
+		} else if !isFollower {
+			rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusForbidden, Message: "Only followers can see the stories of that user"})
+			return
+		}
+	}
+
+	image, dbErr := rt.db.GetStoryImage(storyId, userId)
+	if dbErr.InternalError != nil {
+		if dbErr.Code == database.StateConflict {
+			rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, utils.HttpError{StatusCode: http.StatusConflict, Message: "Story expired or not posted by that user"})
+			return
+		}
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	if authUserId != userId {
+		dbErr = rt.db.MarkStorySeen(storyId, authUserId)
+		if dbErr.InternalError != nil {
+			rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(image)
+}
+
+func (rt *_router) deleteStory(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	storyId := params["story_id"]
+	userId := params["user_id"]
+
+	isOperationSuccessful, dbErr := rt.db.DeleteStory(storyId, userId)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	if !isOperationSuccessful {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: utils.NotUserStoryMessage})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("Story deleted successfully"))
+}
+
+func (rt *_router) getStoryViewers(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	storyId := params["story_id"]
+	userId := params["user_id"]
+
+	if !rt.db.DoesStoryBelongToUser(userId, storyId) {
+		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusConflict, Message: utils.NotUserStoryMessage})
+		return
+	}
+
+	dbViewers, dbErr := rt.db.GetStoryViewers(storyId, userId)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	viewers := make([]StoryViewer, 0, len(dbViewers))
+	for _, dbViewer := range dbViewers {
+		var viewer StoryViewer
+		viewer.User.fromDatabase(dbViewer.User)
+		viewer.SeenAt = dbViewer.SeenAt
+		viewers = append(viewers, viewer)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(StoryViewers{viewers})
+}
+
+func (rt *_router) getStoriesTray(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	userId := params["user_id"]
+
+	dbAuthors, dbErr := rt.db.GetStoriesTray(userId)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	authors := make([]StoryAuthor, 0, len(dbAuthors))
+	for _, dbAuthor := range dbAuthors {
+		var author StoryAuthor
+		author.fromDatabase(dbAuthor)
+		authors = append(authors, author)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(StoriesTray{authors})
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"regexp"
+	"unicode/utf8"
 	"wasaphoto/service/database"
 )
 
@@ -25,24 +26,68 @@ type UserStream struct {
 }
 
 type Photo struct {
-	Id         int64         `json:"id"`
-	Owner      User          `json:"owner"`
-	UploadedAt string        `json:"uploadedAt"`
-	PhotoInfo  PhotoCounters `json:"photoInfo"`
+	Id            int64         `json:"id"`
+	Owner         User          `json:"owner"`
+	UploadedAt    string        `json:"uploadedAt"`
+	AltText       string        `json:"altText"`
+	Place         *Place        `json:"place,omitempty"`
+	PublishAt     string        `json:"publishAt,omitempty"`
+	Visibility    string        `json:"visibility"`
+	CommentPolicy string        `json:"commentPolicy"`
+	LikedByMe     bool          `json:"likedByMe"`
+	MyReaction    string        `json:"myReaction,omitempty"`
+	RepostedByMe  bool          `json:"repostedByMe"`
+	RepostedBy    *User         `json:"repostedBy,omitempty"`
+	RepostedAt    string        `json:"repostedAt,omitempty"`
+	PhotoInfo     PhotoCounters `json:"photoInfo"`
+}
+
+type UploadedPhoto struct {
+	Id       int64    `json:"id"`
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 type Comment struct {
-	Id        int64  `json:"id"`
-	Owner     User   `json:"owner"`
-	Content   string `json:"content"`
-	CreatedAt string `json:"uploadedAt"`
+	Id           int64  `json:"id"`
+	Owner        *User  `json:"owner,omitempty"`
+	Content      string `json:"content"`
+	CreatedAt    string `json:"uploadedAt"`
+	ParentId     int64  `json:"parentId,omitempty"`
+	ReplyCount   int    `json:"replyCount"`
+	Deleted      bool   `json:"deleted,omitempty"`
+	LikesCounter int    `json:"likesCounter"`
+	LikedByMe    bool   `json:"likedByMe"`
+	Edited       bool   `json:"edited"`
+	EditedAt     string `json:"editedAt,omitempty"`
+	Hidden       bool   `json:"hidden,omitempty"`
 }
 
+// fromDatabase leaves the owner of deleted comments out, they only hold the place of their replies
 func (c *Comment) fromDatabase(dbComment database.Comment) {
 	c.Id = dbComment.Id
-	c.Owner.fromDatabase(dbComment.Owner)
+	if !dbComment.Deleted {
+		c.Owner = &User{}
+		c.Owner.fromDatabase(dbComment.Owner)
+	}
 	c.Content = dbComment.Content
 	c.CreatedAt = dbComment.CreatedAt
+	c.ParentId = dbComment.ParentId
+	c.ReplyCount = dbComment.ReplyCount
+	c.Deleted = dbComment.Deleted
+	c.LikesCounter = dbComment.LikesCounter
+	c.LikedByMe = dbComment.LikedByMe
+	c.Edited = dbComment.EditedAt != ""
+	c.EditedAt = dbComment.EditedAt
+	c.Hidden = dbComment.Hidden
+}
+
+type CommentRevision struct {
+	Content   string `json:"content"`
+	WrittenAt string `json:"writtenAt"`
+}
+
+type CommentRevisions struct {
+	Revisions []CommentRevision `json:"revisions"`
 }
 
 type UserIdentifier struct {
@@ -61,15 +106,54 @@ func (u Username) IsValid() bool {
 	return valid
 }
 
+type AltText struct {
+	AltText string `json:"altText"`
+}
+
+// MaxAltTextLength is the maximum number of characters of a photo alternative text
+const MaxAltTextLength = 500
+
+func (a AltText) IsValid() bool {
+	return utf8.RuneCountInString(a.AltText) <= MaxAltTextLength
+}
+
+type PhotoVisibility struct {
+	Visibility string `json:"visibility"`
+}
+
+func (v PhotoVisibility) IsValid() bool {
+	switch v.Visibility {
+	case database.PublicVisibility, database.FollowersVisibility, database.CloseFriendsVisibility, database.PrivateVisibility:
+		return true
+	}
+	return false
+}
+
+type PhotoCommentPolicy struct {
+	CommentPolicy string `json:"commentPolicy"`
+}
+
+func (c PhotoCommentPolicy) IsValid() bool {
+	switch c.CommentPolicy {
+	case database.EveryoneCommentPolicy, database.FollowersCommentPolicy, database.FollowingCommentPolicy, database.NobodyCommentPolicy:
+		return true
+	}
+	return false
+}
+
 type PhotoCounters struct {
-	LikesCounter    int `json:"likesCounter"`
-	CommentsCounter int `json:"commentsCounter"`
+	LikesCounter      int            `json:"likesCounter"`
+	CommentsCounter   int            `json:"commentsCounter"`
+	RepostsCounter    int            `json:"repostsCounter"`
+	ReactionsCounters map[string]int `json:"reactionsCounters"`
 }
 
 type UserProfile struct {
-	UserInfo    User            `json:"user_info"`
-	Photos      []Photo         `json:"photos"`
-	ProfileInfo ProfileCounters `json:"profileInfo"`
+	UserInfo     User            `json:"user_info"`
+	Private      bool            `json:"private"`
+	Relationship Relationship    `json:"relationship"`
+	Photos       []Photo         `json:"photos"`
+	ProfileInfo  ProfileCounters `json:"profileInfo"`
 }
 
 func (u *User) fromDatabase(dbUser database.User) {
@@ -79,6 +163,7 @@ func (u *User) fromDatabase(dbUser database.User) {
 
 func (up *UserProfile) fromDatabase(upDb database.UserProfile) {
 	up.UserInfo.fromDatabase(upDb.UserInfo)
+	up.Private = upDb.Private
 	if upDb.Photos != nil {
 		for _, photo := range upDb.Photos {
 			newPhoto := Photo{}
@@ -98,6 +183,24 @@ func (p *Photo) fromDatabase(dbPhoto database.Photo) {
 	p.Id = dbPhoto.Id
 	p.Owner.fromDatabase(dbPhoto.Owner)
 	p.UploadedAt = dbPhoto.UploadedAt
+	p.AltText = dbPhoto.AltText
+	if dbPhoto.Place != nil {
+		p.Place = &Place{}
+		p.Place.fromDatabase(*dbPhoto.Place)
+	}
+	p.PublishAt = dbPhoto.PublishAt
+	p.Visibility = dbPhoto.Visibility
+	p.CommentPolicy = dbPhoto.CommentPolicy
+	p.LikedByMe = dbPhoto.LikedByMe
+	p.MyReaction = dbPhoto.MyReaction
+	p.RepostedByMe = dbPhoto.RepostedByMe
+	if dbPhoto.RepostedBy != nil {
+		p.RepostedBy = &User{}
+		p.RepostedBy.fromDatabase(*dbPhoto.RepostedBy)
+		p.RepostedAt = dbPhoto.RepostedAt
+	}
 	p.PhotoInfo.LikesCounter = dbPhoto.PhotoInfo.LikesCounter
 	p.PhotoInfo.CommentsCounter = dbPhoto.PhotoInfo.CommentsCounter
+	p.PhotoInfo.RepostsCounter = dbPhoto.PhotoInfo.RepostsCounter
+	p.PhotoInfo.ReactionsCounters = dbPhoto.PhotoInfo.Reactions
 }
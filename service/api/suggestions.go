@@ -0,0 +1,84 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"wasaphoto/service/database"
+	"wasaphoto/service/utils"
+)
+
+// DefaultSuggestionsAmount is the number of suggestions returned when the amount is not given
+const DefaultSuggestionsAmount = 10
+
+type Suggestion struct {
+	User          User     `json:"user"`
+	MutualFollows int      `json:"mutualFollows"`
+	Reasons       []string `json:"reasons"`
+}
+
+type Suggestions struct {
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+func (s *Suggestion) fromDatabase(dbSuggestion database.Suggestion) {
+	s.User.fromDatabase(dbSuggestion.User)
+	s.MutualFollows = dbSuggestion.MutualFollows
+	s.Reasons = make([]string, 0, 3)
+
+	switch {
+	case dbSuggestion.MutualFollows == 1:
+		s.Reasons = append(s.Reasons, fmt.Sprintf("followed by %s", dbSuggestion.MutualFollow))
+	case dbSuggestion.MutualFollows == 2:
+		s.Reasons = append(s.Reasons, fmt.Sprintf("followed by %s and 1 other", dbSuggestion.MutualFollow))
+	case dbSuggestion.MutualFollows > 2:
+		s.Reasons = append(s.Reasons, fmt.Sprintf("followed by %s and %d others", dbSuggestion.MutualFollow,
+			dbSuggestion.MutualFollows-1))
+	}
+
+	switch {
+	case dbSuggestion.Interactions == 1:
+		s.Reasons = append(s.Reasons, "1 like or comment between you")
+	case dbSuggestion.Interactions > 1:
+		s.Reasons = append(s.Reasons, fmt.Sprintf("%d likes and comments between you", dbSuggestion.Interactions))
+	}
+
+	switch {
+	case dbSuggestion.RecentPhotos == 1:
+		s.Reasons = append(s.Reasons, "posted 1 photo this week")
+	case dbSuggestion.RecentPhotos > 1:
+		s.Reasons = append(s.Reasons, fmt.Sprintf("posted %d photos this week", dbSuggestion.RecentPhotos))
+	}
+}
+
+func (rt *_router) getFollowSuggestions(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	userId := params["user_id"]
+
+	var amount int64 = DefaultSuggestionsAmount
+	if r.URL.Query().Has("amount") {
+		var err error
+		amount, err = strconv.ParseInt(r.URL.Query().Get("amount"), 10, 64)
+		if err != nil || amount <= 0 || amount > MaxPageAmount {
+			rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Query paramaters badly formatted"})
+			return
+		}
+	}
+
+	dbSuggestions, dbErr := rt.db.GetFollowSuggestions(userId, amount)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	suggestions := Suggestions{Suggestions: make([]Suggestion, 0, len(dbSuggestions))}
+	for _, dbSuggestion := range dbSuggestions {
+		var suggestion Suggestion
+		suggestion.fromDatabase(dbSuggestion)
+		suggestions.Suggestions = append(suggestions.Suggestions, suggestion)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(suggestions)
+}
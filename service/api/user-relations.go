@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
 	"wasaphoto/service/database"
 	"wasaphoto/service/utils"
 )
@@ -13,14 +14,22 @@ type userList struct {
 }
 
 func (rt *_router) banUser(w http.ResponseWriter, r *http.Request, params map[string]int64) {
-	rt.targetUser(w, params, database.BanTable)
+	rt.targetUser(w, r, params, database.BanTable)
 }
 
 func (rt *_router) followUser(w http.ResponseWriter, r *http.Request, params map[string]int64) {
-	rt.targetUser(w, params, database.FollowTable)
+	rt.targetUser(w, r, params, database.FollowTable)
 }
 
-func (rt *_router) targetUser(w http.ResponseWriter, params map[string]int64, entityTable string) {
+func (rt *_router) muteUser(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	rt.targetUser(w, r, params, database.MuteTable)
+}
+
+func (rt *_router) addCloseFriend(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	rt.targetUser(w, r, params, database.CloseFriendTable)
+}
+
+func (rt *_router) targetUser(w http.ResponseWriter, r *http.Request, params map[string]int64, entityTable string) {
 
 	authUserId := params["token"]
 	targetedUserId := params["targeted_user_id"]
@@ -42,11 +51,46 @@ func (rt *_router) targetUser(w http.ResponseWriter, params map[string]int64, en
 			rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusForbidden, Message: "You can't follow who banned you"})
 			return
 		}
-	} else if entityTable != database.BanTable {
+
+		// Private accounts have to approve their followers: a request is sent instead, unless already following
+		isPrivate, dbErr := rt.db.IsUserPrivate(targetedUserId)
+		if dbErr.InternalError != nil {
+			rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+			return
+		}
+
+		var isFollowing bool
+		isFollowing, dbErr = rt.db.IsUserTargeted(authUserId, targetedUserId, database.FollowTable)
+		if dbErr.InternalError != nil {
+			rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+			return
+		}
+
+		if isPrivate && !isFollowing {
+			rt.requestFollow(w, authUserId, targetedUserId)
+			return
+		}
+	} else if entityTable != database.BanTable && entityTable != database.MuteTable && entityTable != database.CloseFriendTable {
 		return
 	}
 
-	isOperationSuccessful, dbErr := rt.db.TargetUser(authUserId, targetedUserId, entityTable)
+	var isOperationSuccessful bool
+	var dbErr database.DbError
+	if entityTable == database.BanTable {
+		// Likes and comments of the banned user are kept, unless a purge is requested
+		var purge bool
+		if r.URL.Query().Has("purge") {
+			var err error
+			purge, err = strconv.ParseBool(r.URL.Query().Get("purge"))
+			if err != nil {
+				rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Query paramaters badly formatted"})
+				return
+			}
+		}
+		isOperationSuccessful, dbErr = rt.db.BanUser(authUserId, targetedUserId, purge)
+	} else {
+		isOperationSuccessful, dbErr = rt.db.TargetUser(authUserId, targetedUserId, entityTable)
+	}
 	if dbErr.InternalError != nil {
 		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
 		return
@@ -64,10 +108,30 @@ func (rt *_router) targetUser(w http.ResponseWriter, params map[string]int64, en
 		_, _ = w.Write([]byte("User banned successfully"))
 	case database.FollowTable:
 		_, _ = w.Write([]byte("User followed successfully"))
+	case database.MuteTable:
+		_, _ = w.Write([]byte("User muted successfully"))
+	case database.CloseFriendTable:
+		_, _ = w.Write([]byte("User added to close friends successfully"))
 	}
 
 }
 
+func (rt *_router) requestFollow(w http.ResponseWriter, authUserId int64, targetedUserId int64) {
+	_, dbErr := rt.db.RequestFollow(authUserId, targetedUserId)
+	if dbErr.InternalError != nil {
+		if dbErr.Code == database.StateConflict {
+			rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, utils.HttpError{StatusCode: http.StatusConflict, Message: "Follow request already sent"})
+			return
+		}
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusAccepted)
+	_, _ = w.Write([]byte("Follow request sent successfully"))
+}
+
 func (rt *_router) unbanUser(w http.ResponseWriter, r *http.Request, params map[string]int64) {
 	rt.untargetUser(w, params, database.BanTable)
 }
@@ -76,6 +140,14 @@ func (rt *_router) unfollowUser(w http.ResponseWriter, r *http.Request, params m
 	rt.untargetUser(w, params, database.FollowTable)
 }
 
+func (rt *_router) unmuteUser(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	rt.untargetUser(w, params, database.MuteTable)
+}
+
+func (rt *_router) removeCloseFriend(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	rt.untargetUser(w, params, database.CloseFriendTable)
+}
+
 func (rt *_router) untargetUser(w http.ResponseWriter, params map[string]int64, entityTable string) {
 	authUserId := params["token"]
 	targetedUserId := params["targeted_user_id"]
@@ -95,7 +167,7 @@ func (rt *_router) untargetUser(w http.ResponseWriter, params map[string]int64,
 			rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusForbidden, Message: "You can't unfollow who banned you"})
 			return
 		}
-	} else if entityTable != database.BanTable {
+	} else if entityTable != database.BanTable && entityTable != database.MuteTable && entityTable != database.CloseFriendTable {
 		return
 	}
 
@@ -105,6 +177,22 @@ func (rt *_router) untargetUser(w http.ResponseWriter, params map[string]int64,
 		return
 	}
 
+	if !isOperationSuccessful && entityTable == database.FollowTable {
+		// Unfollowing a private account before being approved withdraws the request
+		isOperationSuccessful, dbErr = rt.db.DeleteFollowRequest(targetedUserId, authUserId)
+		if dbErr.InternalError != nil {
+			rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+			return
+		}
+
+		if isOperationSuccessful {
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte("Follow request withdrawn successfully"))
+			return
+		}
+	}
+
 	if !isOperationSuccessful {
 		rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusNotFound, Message: "Target requested not found"})
 		return
@@ -117,43 +205,88 @@ func (rt *_router) untargetUser(w http.ResponseWriter, params map[string]int64,
 		_, _ = w.Write([]byte("User unbanned successfully"))
 	case database.FollowTable:
 		_, _ = w.Write([]byte("User unfollowed successfully"))
+	case database.MuteTable:
+		_, _ = w.Write([]byte("User unmuted successfully"))
+	case database.CloseFriendTable:
+		_, _ = w.Write([]byte("User removed from close friends successfully"))
 	}
 
 }
 
+type UsersPage struct {
+	Users      []User `json:"users"`
+	Total      int    `json:"total"`
+	NextCursor int64  `json:"nextCursor,omitempty"`
+}
+
+func (p *UsersPage) fromDatabase(dbPage database.UsersPage) {
+	p.Users = make([]User, 0, len(dbPage.Users))
+	for _, dbUser := range dbPage.Users {
+		var user User
+		user.fromDatabase(dbUser)
+		p.Users = append(p.Users, user)
+	}
+	p.Total = dbPage.Total
+	p.NextCursor = dbPage.NextCursor
+}
+
 func (rt *_router) getFollowedUsers(w http.ResponseWriter, r *http.Request, params map[string]int64) {
-	rt.getUsersList(w, r, params, database.FollowTable)
+	rt.getUsersList(w, r, params, database.FollowingList)
+}
+
+func (rt *_router) getFollowers(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	rt.getUsersList(w, r, params, database.FollowersList)
 }
 
 func (rt *_router) getBannedUsers(w http.ResponseWriter, r *http.Request, params map[string]int64) {
-	rt.getUsersList(w, r, params, database.BanTable)
+	rt.getUsersList(w, r, params, database.BannedList)
 }
 
-func (rt *_router) getUsersList(w http.ResponseWriter, r *http.Request, params map[string]int64, entityTable string) {
+func (rt *_router) getMutedUsers(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	rt.getUsersList(w, r, params, database.MutedList)
+}
+
+func (rt *_router) getCloseFriends(w http.ResponseWriter, r *http.Request, params map[string]int64) {
+	rt.getUsersList(w, r, params, database.CloseFriendsList)
+}
+
+// getUsersList sends a page of a list of the user. Who follows and who is followed are visible as the user photos,
+// the banned and muted users only to the user.
+func (rt *_router) getUsersList(w http.ResponseWriter, r *http.Request, params map[string]int64, list string) {
+	authUserId := params["token"]
 	userId := params["user_id"]
 
-	dbUsers, dbErr := rt.db.GetUsersList(userId, entityTable)
-	if dbErr.InternalError != nil {
-		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+	cursor, amount, err := getCursorParams(r)
+	if err != nil {
+		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Query paramaters badly formatted"})
 		return
 	}
 
-	var users []User
-	if dbUsers == nil {
-		users = make([]User, 0)
-	}
+	if list == database.FollowingList || list == database.FollowersList {
+		userIsBanned, dbErr := rt.db.IsUserTargeted(userId, authUserId, database.BanTable)
+		if dbErr.InternalError != nil {
+			rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+			return
+		} else if userIsBanned {
+			rt.LoggerAndHttpErrorSender(w, nil, utils.HttpError{StatusCode: http.StatusForbidden, Message: utils.BannedMessage})
+			return
+		}
 
-	for _, dbUser := range dbUsers {
-		var user User
-		user.fromDatabase(dbUser)
-		users = append(users, user)
+		if !rt.isContentVisible(w, authUserId, userId) {
+			return
+		}
 	}
 
-	userList := userList{
-		Users: users,
+	dbPage, dbErr := rt.db.GetUsersList(userId, list, cursor, amount)
+	if dbErr.InternalError != nil {
+		rt.LoggerAndHttpErrorSender(w, dbErr.InternalError, dbErr.ToHttp())
+		return
 	}
 
-	w.WriteHeader(http.StatusOK)
+	var page UsersPage
+	page.fromDatabase(dbPage)
+
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(userList)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(page)
 }
@@ -3,7 +3,6 @@ package api
 import (
 	"encoding/json"
 	"net/http"
-	"strconv"
 	"wasaphoto/service/utils"
 )
 
@@ -12,13 +11,7 @@ func (rt *_router) getMyStream(w http.ResponseWriter, r *http.Request, params ma
 	var photos []Photo
 
 	// get query params
-	offset, err := strconv.ParseInt(r.URL.Query().Get("offset"), 10, 64)
-	if err != nil {
-		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Query paramaters badly formatted"})
-		return
-	}
-
-	amount, err := strconv.ParseInt(r.URL.Query().Get("amount"), 10, 64)
+	offset, amount, err := getPaginationParams(r)
 	if err != nil {
 		rt.LoggerAndHttpErrorSender(w, err, utils.HttpError{StatusCode: http.StatusBadRequest, Message: "Query paramaters badly formatted"})
 		return
@@ -0,0 +1,293 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/mattn/go-sqlite3"
+)
+
+// albumPhotoIsVisible is the condition selecting the album photos shown to the viewer
+var albumPhotoIsVisible = fmt.Sprintf("%s AND %s", listedPhoto, photoVisibleTo("Viewer.id"))
+
+// albumColumns are the columns read by scanAlbums, in order. Queries using them must join Album with User on the album
+// owner, and with albumViewer. When no cover has been picked, or the viewer can't see it, the first photo of the album
+// visible to the viewer is used.
+var albumColumns = "Album.id, Album.owner, User.name, Album.name, coalesce((SELECT Photo.id FROM Photo WHERE " +
+	"Photo.id=Album.cover AND " + albumPhotoIsVisible + "), (SELECT photo FROM AlbumPhoto, Photo WHERE album=Album.id AND " +
+	"Photo.id=photo AND " + albumPhotoIsVisible + " ORDER BY position LIMIT 1)), (SELECT count(*) FROM AlbumPhoto, Photo " +
+	"WHERE album=Album.id AND Photo.id=photo AND " + albumPhotoIsVisible + "), Album.created_at"
+
+// albumViewer is the table holding the id of the user the albums are shown to, bound as its only parameter
+const albumViewer = "(SELECT ? AS id) AS Viewer"
+
+func (db *appdbimpl) DoesAlbumBelongToUser(userId int64, album int64) bool {
+	var count int
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE id=? AND owner=?", AlbumTable)
+	err := db.c.QueryRow(query, album, userId).Scan(&count)
+
+	if err != nil {
+		return false
+	}
+
+	return count > 0
+}
+
+func (db *appdbimpl) CreateAlbum(owner int64, name string) (int64, DbError) {
+	var dbErr DbError
+	var id int64
+
+	query := fmt.Sprintf("INSERT INTO %s (owner, name) VALUES (?, ?)", AlbumTable)
+	res, err := db.c.Exec(query, owner, name)
+	if err != nil {
+		dbErr.InternalError = err
+		return id, dbErr
+	}
+
+	id, err = res.LastInsertId()
+	if err != nil {
+		dbErr.InternalError = err
+	}
+
+	return id, dbErr
+}
+
+// UpdateAlbum renames the album and sets its cover, a cover equal to 0 removes it. The cover has to be a photo of the
+// album.
+func (db *appdbimpl) UpdateAlbum(album int64, owner int64, name string, cover int64) (bool, DbError) {
+	var dbErr DbError
+	var affected int64
+
+	var coverId sql.NullInt64
+	if cover != 0 {
+		coverId = sql.NullInt64{Int64: cover, Valid: true}
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET name=?, cover=? WHERE id=? AND owner=? AND "+
+		"(? IS NULL OR EXISTS(SELECT * FROM %s WHERE album=%s.id AND photo=?))", AlbumTable, AlbumPhotoTable, AlbumTable)
+	res, err := db.c.Exec(query, name, coverId, album, owner, coverId, coverId)
+	if err != nil {
+		dbErr.InternalError = err
+		return false, dbErr
+	} else {
+		affected, _ = res.RowsAffected()
+	}
+
+	return affected > 0, dbErr
+}
+
+// Album has to belong to the authenticated user
+func (db *appdbimpl) DeleteAlbum(album int64, owner int64) (bool, DbError) {
+	var dbErr DbError
+	var affected int64
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE id=? AND owner=?", AlbumTable)
+	res, err := db.c.Exec(query, album, owner)
+	if err != nil {
+		dbErr.InternalError = err
+		return false, dbErr
+	} else {
+		affected, _ = res.RowsAffected()
+	}
+
+	return affected > 0, dbErr
+}
+
+// GetAlbum returns the album, as seen by the viewer, if it belongs to the given user
+func (db *appdbimpl) GetAlbum(album int64, owner int64, viewer int64) (Album, DbError) {
+	var dbErr DbError
+
+	query := fmt.Sprintf("SELECT %s FROM %s, %s, %s WHERE %s.owner=%s.id AND %s.id=? AND %s.owner=?", albumColumns,
+		AlbumTable, UserTable, albumViewer, AlbumTable, UserTable, AlbumTable, AlbumTable)
+	rows, err := db.c.Query(query, viewer, album, owner)
+	if err != nil {
+		dbErr.InternalError = err
+		return Album{}, dbErr
+	}
+
+	defer rows.Close()
+
+	albums, dbErr := scanAlbums(rows)
+	if dbErr.InternalError != nil {
+		return Album{}, dbErr
+	}
+
+	if len(albums) == 0 {
+		dbErr.InternalError = sql.ErrNoRows
+		dbErr.Code = StateConflict
+		return Album{}, dbErr
+	}
+
+	return albums[0], dbErr
+}
+
+func (db *appdbimpl) GetUserAlbums(owner int64, viewer int64, amount int64, offset int64) ([]Album, DbError) {
+	var dbErr DbError
+
+	query := fmt.Sprintf("SELECT %s FROM %s, %s, %s WHERE %s.owner=%s.id AND %s.owner=? ORDER BY %s.created_at DESC, "+
+		"%s.id DESC LIMIT ? OFFSET ?", albumColumns, AlbumTable, UserTable, albumViewer, AlbumTable, UserTable, AlbumTable,
+		AlbumTable, AlbumTable)
+	rows, err := db.c.Query(query, viewer, owner, amount, offset)
+	if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
+	}
+
+	defer rows.Close()
+
+	return scanAlbums(rows)
+}
+
+// scanAlbums reads every row selected with albumColumns
+func scanAlbums(rows *sql.Rows) ([]Album, DbError) {
+	var dbErr DbError
+	var albums []Album
+
+	for rows.Next() {
+		var album Album
+		var cover sql.NullInt64
+		err := rows.Scan(&album.Id, &album.Owner.Id, &album.Owner.Username, &album.Name, &cover, &album.PhotosCounter,
+			&album.CreatedAt)
+		if err != nil {
+			dbErr.InternalError = err
+			return nil, dbErr
+		}
+
+		album.Cover = cover.Int64
+		albums = append(albums, album)
+	}
+
+	err := rows.Err()
+	if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
+	}
+
+	return albums, dbErr
+}
+
+// GetAlbumPhotos returns the photos of the album the viewer can see, in the order chosen by its owner
+func (db *appdbimpl) GetAlbumPhotos(album int64, viewer int64, amount int64, offset int64) ([]Photo, DbError) {
+	var dbErr DbError
+
+	query := fmt.Sprintf("SELECT %s FROM %s, %s, %s, %s WHERE Photo.owner=User.id AND Photo.id=%s.photo AND %s.album=? "+
+		"AND %s ORDER BY %s.position LIMIT ? OFFSET ?", photoColumns, PhotoTable, UserTable, AlbumPhotoTable, albumViewer,
+		AlbumPhotoTable, AlbumPhotoTable, albumPhotoIsVisible, AlbumPhotoTable)
+	rows, err := db.c.Query(query, viewer, album, amount, offset)
+	if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
+	}
+
+	defer rows.Close()
+
+	return db.scanPhotos(rows, viewer)
+}
+
+// AddPhotoToAlbum appends the photo at the end of the album. Photo and album have to belong to the same user.
+func (db *appdbimpl) AddPhotoToAlbum(album int64, photo int64) (bool, DbError) {
+	var dbErr DbError
+	var affected int64
+
+	query := fmt.Sprintf("INSERT INTO %s (album, photo, position) SELECT ?, ?, coalesce(max(position), 0) + 1 FROM %s "+
+		"WHERE album=?", AlbumPhotoTable, AlbumPhotoTable)
+	res, err := db.c.Exec(query, album, photo, album)
+	if err != nil {
+		var sqlErr sqlite3.Error
+		dbErr.InternalError = err
+		if errors.As(err, &sqlErr) {
+			if errors.Is(sqlErr.ExtendedCode, sqlite3.ErrConstraintPrimaryKey) {
+				dbErr.Code = StateConflict
+			}
+		}
+		return false, dbErr
+	} else {
+		affected, _ = res.RowsAffected()
+	}
+
+	return affected > 0, dbErr
+}
+
+// RemovePhotoFromAlbum removes the photo from the album, and from its cover if it was picked as such
+func (db *appdbimpl) RemovePhotoFromAlbum(album int64, photo int64) (bool, DbError) {
+	var dbErr DbError
+	var affected int64
+
+	tx, err := db.c.Begin()
+	if err != nil {
+		dbErr.InternalError = err
+		return false, dbErr
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE album=? AND photo=?", AlbumPhotoTable)
+	res, err := tx.Exec(query, album, photo)
+	if err == nil {
+		affected, _ = res.RowsAffected()
+		query = fmt.Sprintf("UPDATE %s SET cover=NULL WHERE id=? AND cover=?", AlbumTable)
+		_, err = tx.Exec(query, album, photo)
+	}
+
+	if err == nil {
+		err = tx.Commit()
+	} else {
+		_ = tx.Rollback()
+	}
+
+	if err != nil {
+		dbErr.InternalError = err
+		return false, dbErr
+	}
+
+	return affected > 0, dbErr
+}
+
+// ReorderAlbum sets the order of the album photos, photos has to contain every photo of the album exactly once
+func (db *appdbimpl) ReorderAlbum(album int64, photos []int64) (bool, DbError) {
+	var dbErr DbError
+
+	tx, err := db.c.Begin()
+	if err != nil {
+		dbErr.InternalError = err
+		return false, dbErr
+	}
+
+	var albumSize int
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE album=?", AlbumPhotoTable)
+	err = tx.QueryRow(query, album).Scan(&albumSize)
+
+	isPermutation := err == nil && albumSize == len(photos)
+	query = fmt.Sprintf("UPDATE %s SET position=? WHERE album=? AND photo=?", AlbumPhotoTable)
+	for position, photo := range photos {
+		if err != nil || !isPermutation {
+			break
+		}
+
+		var res sql.Result
+		res, err = tx.Exec(query, position+1, album, photo)
+		if err == nil {
+			affected, _ := res.RowsAffected()
+			isPermutation = affected > 0
+		}
+	}
+
+	if err == nil && isPermutation {
+		// Every photo of the album has been moved once, so no position can be left duplicated
+		var distinctPositions int
+		query = fmt.Sprintf("SELECT count(DISTINCT position) FROM %s WHERE album=?", AlbumPhotoTable)
+		err = tx.QueryRow(query, album).Scan(&distinctPositions)
+		isPermutation = distinctPositions == albumSize
+	}
+
+	if err == nil && isPermutation {
+		err = tx.Commit()
+	} else {
+		_ = tx.Rollback()
+	}
+
+	if err != nil {
+		dbErr.InternalError = err
+		return false, dbErr
+	}
+
+	return isPermutation, dbErr
+}
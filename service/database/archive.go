@@ -0,0 +1,57 @@
+package database
+
+import (
+	"fmt"
+)
+
+// ArchivePhoto hides the photo from every list, keeping its likes and comments. Photo has to belong to the
+// authenticated user.
+func (db *appdbimpl) ArchivePhoto(photo int64, user int64) (bool, DbError) {
+	var dbErr DbError
+	var affected int64
+
+	query := fmt.Sprintf("UPDATE %s SET archived_at=current_timestamp WHERE id=? AND owner=? AND archived_at IS NULL", PhotoTable)
+	res, err := db.c.Exec(query, photo, user)
+	if err != nil {
+		dbErr.InternalError = err
+		return false, dbErr
+	} else {
+		affected, _ = res.RowsAffected()
+	}
+
+	return affected > 0, dbErr
+}
+
+// RestorePhoto lists again an archived photo. Photo has to belong to the authenticated user.
+func (db *appdbimpl) RestorePhoto(photo int64, user int64) (bool, DbError) {
+	var dbErr DbError
+	var affected int64
+
+	query := fmt.Sprintf("UPDATE %s SET archived_at=NULL WHERE id=? AND owner=? AND archived_at IS NOT NULL", PhotoTable)
+	res, err := db.c.Exec(query, photo, user)
+	if err != nil {
+		dbErr.InternalError = err
+		return false, dbErr
+	} else {
+		affected, _ = res.RowsAffected()
+	}
+
+	return affected > 0, dbErr
+}
+
+// GetArchivedPhotos returns the archived photos of the user, latest archived first
+func (db *appdbimpl) GetArchivedPhotos(user int64, amount int64, offset int64) ([]Photo, DbError) {
+	var dbErr DbError
+
+	query := fmt.Sprintf("SELECT %s FROM %s, %s WHERE Photo.owner=User.id AND Photo.owner=? AND Photo.archived_at IS NOT NULL "+
+		"ORDER BY Photo.archived_at DESC, Photo.id DESC LIMIT ? OFFSET ?", photoColumns, PhotoTable, UserTable)
+	rows, err := db.c.Query(query, user, amount, offset)
+	if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
+	}
+
+	defer rows.Close()
+
+	return db.scanPhotos(rows, user)
+}
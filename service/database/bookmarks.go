@@ -0,0 +1,112 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// GetPhotoOwner returns the identifier of the user who uploaded the photo
+func (db *appdbimpl) GetPhotoOwner(photo int64) (int64, DbError) {
+	var dbErr DbError
+	var owner int64
+
+	query := fmt.Sprintf("SELECT owner FROM %s WHERE id=?", PhotoTable)
+	err := db.c.QueryRow(query, photo).Scan(&owner)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			dbErr.Code = StateConflict
+		}
+		dbErr.InternalError = err
+	}
+
+	return owner, dbErr
+}
+
+// SavePhoto bookmarks the photo in the given collection, moving it there if it was already saved
+func (db *appdbimpl) SavePhoto(user int64, photo int64, collection string) DbError {
+	var dbErr DbError
+
+	query := fmt.Sprintf("INSERT INTO %s (owner, photo, collection) VALUES (?, ?, ?) "+
+		"ON CONFLICT (owner, photo) DO UPDATE SET collection=excluded.collection", BookmarkTable)
+	_, err := db.c.Exec(query, user, photo, collection)
+	if err != nil {
+		dbErr.InternalError = err
+	}
+
+	return dbErr
+}
+
+func (db *appdbimpl) UnsavePhoto(user int64, photo int64) (bool, DbError) {
+	var dbErr DbError
+	var affected int64
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE owner=? AND photo=?", BookmarkTable)
+	res, err := db.c.Exec(query, user, photo)
+	if err != nil {
+		dbErr.InternalError = err
+		return false, dbErr
+	} else {
+		affected, _ = res.RowsAffected()
+	}
+
+	return affected > 0, dbErr
+}
+
+// savedPhotoIsVisible is the condition hiding the bookmarks of photos not listed anymore or that the user who saved
+// them can't see anymore
+var savedPhotoIsVisible = fmt.Sprintf("%s AND %s", listedPhoto, photoVisibleTo(BookmarkTable+".owner"))
+
+// GetSavedPhotos returns the photos saved by the user, latest saved first. If collection is empty, the photos of every
+// collection are returned.
+func (db *appdbimpl) GetSavedPhotos(user int64, collection string, amount int64, offset int64) ([]Photo, DbError) {
+	var dbErr DbError
+
+	query := fmt.Sprintf("SELECT %s FROM %s, %s, %s WHERE Photo.owner=User.id AND Photo.id=%s.photo AND %s.owner=? "+
+		"AND (?='' OR %s.collection=?) AND %s ORDER BY %s.created_at DESC, Photo.id DESC LIMIT ? OFFSET ?", photoColumns,
+		PhotoTable, UserTable, BookmarkTable, BookmarkTable, BookmarkTable, BookmarkTable, savedPhotoIsVisible, BookmarkTable)
+	rows, err := db.c.Query(query, user, collection, collection, amount, offset)
+	if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
+	}
+
+	defer rows.Close()
+
+	return db.scanPhotos(rows, user)
+}
+
+// GetSavedCollections returns the collections of the user bookmarks, with the number of photos in each one
+func (db *appdbimpl) GetSavedCollections(user int64) ([]SavedCollection, DbError) {
+	var dbErr DbError
+	var collections []SavedCollection
+
+	query := fmt.Sprintf("SELECT %s.collection, count(*) FROM %s, %s WHERE Photo.id=%s.photo AND %s.owner=? AND %s "+
+		"GROUP BY %s.collection ORDER BY %s.collection", BookmarkTable, BookmarkTable, PhotoTable, BookmarkTable,
+		BookmarkTable, savedPhotoIsVisible, BookmarkTable, BookmarkTable)
+	rows, err := db.c.Query(query, user)
+	if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var collection SavedCollection
+		err = rows.Scan(&collection.Name, &collection.PhotosCounter)
+		if err != nil {
+			dbErr.InternalError = err
+			return nil, dbErr
+		}
+		collections = append(collections, collection)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
+	}
+
+	return collections, dbErr
+}
@@ -0,0 +1,51 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mattn/go-sqlite3"
+)
+
+// LikeComment adds the like of the user to the comment, if the comment is on the photo, neither deleted nor hidden
+func (db *appdbimpl) LikeComment(authUser int64, photo int64, comment int64) (bool, DbError) {
+	var dbErr DbError
+	var affected int64
+
+	query := fmt.Sprintf("INSERT INTO %s (owner, comment) SELECT ?, id FROM %s WHERE id=? AND photo=? AND "+
+		"deleted_at IS NULL AND hidden_at IS NULL", CommentLikeTable, CommentTable)
+	res, err := db.c.Exec(query, authUser, comment, photo)
+
+	if err != nil {
+		var sqlErr sqlite3.Error
+		if errors.As(err, &sqlErr) {
+			if errors.Is(sqlErr.ExtendedCode, sqlite3.ErrConstraintPrimaryKey) {
+				dbErr.Code = StateConflict
+			}
+		}
+		dbErr.InternalError = err
+		return false, dbErr
+	} else {
+		affected, _ = res.RowsAffected()
+	}
+
+	return affected > 0, dbErr
+}
+
+// UnlikeComment removes the like of the user from the comment of the photo
+func (db *appdbimpl) UnlikeComment(authUser int64, photo int64, comment int64) (bool, DbError) {
+	var dbErr DbError
+	var affected int64
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE owner=? AND comment IN (SELECT id FROM %s WHERE id=? AND photo=?)",
+		CommentLikeTable, CommentTable)
+	res, err := db.c.Exec(query, authUser, comment, photo)
+
+	if err != nil {
+		dbErr.InternalError = err
+		return false, dbErr
+	} else {
+		affected, _ = res.RowsAffected()
+	}
+
+	return affected > 0, dbErr
+}
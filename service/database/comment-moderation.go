@@ -0,0 +1,67 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"wasaphoto/service/globaltime"
+)
+
+// HideComment hides, or shows again, a comment on a photo of the user: hidden comments are shown only to their author
+// and to the photo owner. If banCommenter is true the author of the comment is banned too, unless it is the user.
+func (db *appdbimpl) HideComment(photo int64, photoOwner int64, comment int64, hidden bool, banCommenter bool) (bool, DbError) {
+	var dbErr DbError
+	var affected int64
+
+	tx, err := db.c.Begin()
+	if err != nil {
+		dbErr.InternalError = err
+		return false, dbErr
+	}
+
+	var hiddenAt interface{}
+	if hidden {
+		hiddenAt = formatTimestamp(globaltime.Now())
+	}
+
+	// Hiding again keeps the time the comment was first hidden
+	query := fmt.Sprintf("UPDATE %[1]s SET hidden_at=CASE WHEN ? IS NULL THEN NULL ELSE coalesce(hidden_at, ?) END "+
+		"WHERE id=? AND photo=? AND deleted_at IS NULL AND EXISTS(SELECT * FROM %[2]s WHERE %[2]s.id=? AND "+
+		"%[2]s.owner=?)", CommentTable, PhotoTable)
+	res, err := tx.Exec(query, hiddenAt, hiddenAt, comment, photo, photo, photoOwner)
+	if err == nil {
+		affected, _ = res.RowsAffected()
+	}
+
+	if err == nil && affected > 0 && banCommenter {
+		err = banCommentOwner(tx, photoOwner, comment)
+	}
+
+	if err == nil {
+		err = tx.Commit()
+	} else {
+		_ = tx.Rollback()
+	}
+
+	if err != nil {
+		dbErr.InternalError = err
+		return false, dbErr
+	}
+
+	return affected > 0, dbErr
+}
+
+// banCommentOwner bans the author of the comment on behalf of the photo owner, keeping their likes and comments.
+// Nothing is done if the author is the photo owner or is already banned.
+func banCommentOwner(tx *sql.Tx, photoOwner int64, comment int64) error {
+	var commentOwner int64
+	var alreadyBanned bool
+	query := fmt.Sprintf("SELECT owner, EXISTS(SELECT * FROM %s WHERE banning=? AND banned=%s.owner) FROM %s WHERE id=?",
+		BanTable, CommentTable, CommentTable)
+	err := tx.QueryRow(query, photoOwner, comment).Scan(&commentOwner, &alreadyBanned)
+	if err != nil || commentOwner == photoOwner || alreadyBanned {
+		return err
+	}
+
+	_, dbErr := banUser(tx, photoOwner, commentOwner, false)
+	return dbErr.InternalError
+}
@@ -0,0 +1,71 @@
+package database
+
+import (
+	"fmt"
+)
+
+// Who can comment a photo, besides its owner
+const (
+	EveryoneCommentPolicy  string = "everyone"
+	FollowersCommentPolicy string = "followers"
+	FollowingCommentPolicy string = "following"
+	NobodyCommentPolicy    string = "nobody"
+)
+
+// Results of the comment check of a photo
+const (
+	CommentAllowed = iota
+	CommentFollowersOnly
+	CommentFollowingOnly
+	CommentsDisabled
+)
+
+// Photo has to belong to the authenticated user
+func (db *appdbimpl) SetPhotoCommentPolicy(photo int64, user int64, policy string) (bool, DbError) {
+	var dbErr DbError
+	var affected int64
+
+	query := fmt.Sprintf("UPDATE %s SET comment_policy=? WHERE id=? AND owner=?", PhotoTable)
+	res, err := db.c.Exec(query, policy, photo, user)
+
+	if err != nil {
+		dbErr.InternalError = err
+		return false, dbErr
+	} else {
+		affected, _ = res.RowsAffected()
+	}
+
+	return affected > 0, dbErr
+}
+
+// GetCommentAccess tells whether the user can comment the photo according to its comment policy and, if not, why. The
+// owner can always comment their photos. Whether the user can see the photo is checked by GetPhotoAccess.
+func (db *appdbimpl) GetCommentAccess(photo int64, commenter int64) (int, DbError) {
+	var dbErr DbError
+	var policy string
+	var isOwner, followsOwner, followedByOwner bool
+
+	query := fmt.Sprintf("SELECT comment_policy, owner=?, "+
+		"EXISTS(SELECT * FROM %[2]s WHERE follower=? AND following=%[1]s.owner), "+
+		"EXISTS(SELECT * FROM %[2]s WHERE follower=%[1]s.owner AND following=?) FROM %[1]s WHERE id=?", PhotoTable,
+		FollowTable)
+	err := db.c.QueryRow(query, commenter, commenter, commenter, photo).Scan(&policy, &isOwner, &followsOwner,
+		&followedByOwner)
+	if err != nil {
+		dbErr.InternalError = err
+		return CommentsDisabled, dbErr
+	}
+
+	switch {
+	case isOwner:
+		return CommentAllowed, dbErr
+	case policy == NobodyCommentPolicy:
+		return CommentsDisabled, dbErr
+	case policy == FollowersCommentPolicy && !followsOwner:
+		return CommentFollowersOnly, dbErr
+	case policy == FollowingCommentPolicy && !followedByOwner:
+		return CommentFollowingOnly, dbErr
+	}
+
+	return CommentAllowed, dbErr
+}
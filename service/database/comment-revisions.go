@@ -0,0 +1,97 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"wasaphoto/service/globaltime"
+)
+
+// EditComment replaces the content of the comment of the user, if it is not deleted and was posted after
+// editableSince. The replaced content is kept as a revision.
+func (db *appdbimpl) EditComment(photo int64, commentOwner int64, comment int64, content string, editableSince time.Time) (bool, DbError) {
+	var dbErr DbError
+	var affected int64
+
+	tx, err := db.c.Begin()
+	if err != nil {
+		dbErr.InternalError = err
+		return false, dbErr
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (comment, content, written_at) SELECT id, content, coalesce(edited_at, "+
+		"created_at) FROM %s WHERE id=? AND photo=? AND owner=? AND deleted_at IS NULL AND created_at>=?",
+		CommentRevisionTable, CommentTable)
+	res, err := tx.Exec(query, comment, photo, commentOwner, formatTimestamp(editableSince))
+	if err == nil {
+		affected, _ = res.RowsAffected()
+	}
+
+	if err == nil && affected > 0 {
+		query = fmt.Sprintf("UPDATE %s SET content=?, edited_at=? WHERE id=?", CommentTable)
+		_, err = tx.Exec(query, content, formatTimestamp(globaltime.Now()), comment)
+	}
+
+	if err == nil {
+		err = tx.Commit()
+	} else {
+		_ = tx.Rollback()
+	}
+
+	if err != nil {
+		dbErr.InternalError = err
+		return false, dbErr
+	}
+
+	return affected > 0, dbErr
+}
+
+// GetCommentRevisions returns every version of the comment, oldest first, the current one last. Deleted comments have
+// no revisions.
+func (db *appdbimpl) GetCommentRevisions(comment int64) ([]CommentRevision, DbError) {
+	var dbErr DbError
+	var revisions []CommentRevision
+
+	var current CommentRevision
+	var editedAt sql.NullString
+	query := fmt.Sprintf("SELECT content, created_at, edited_at FROM %s WHERE id=? AND deleted_at IS NULL", CommentTable)
+	err := db.c.QueryRow(query, comment).Scan(&current.Content, &current.WrittenAt, &editedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, dbErr
+	} else if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
+	}
+
+	if editedAt.Valid {
+		current.WrittenAt = editedAt.String
+	}
+
+	query = fmt.Sprintf("SELECT content, written_at FROM %s WHERE comment=? ORDER BY id", CommentRevisionTable)
+	rows, err := db.c.Query(query, comment)
+	if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var revision CommentRevision
+		err = rows.Scan(&revision.Content, &revision.WrittenAt)
+		if err != nil {
+			dbErr.InternalError = err
+			return nil, dbErr
+		}
+		revisions = append(revisions, revision)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
+	}
+
+	return append(revisions, current), dbErr
+}
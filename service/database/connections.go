@@ -0,0 +1,188 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// followPathBatch is the maximum number of users expanded by a single query of the follow path search
+const followPathBatch = 500
+
+// notBannedWith is the condition excluding the users banned by, or who banned, the user bound to both its parameters
+func notBannedWith(column string) string {
+	return fmt.Sprintf("%[1]s NOT IN (SELECT banned FROM %[2]s WHERE banning=?) AND %[1]s NOT IN (SELECT banning FROM %[2]s WHERE banned=?)",
+		column, BanTable)
+}
+
+// GetMutualFollows returns the first amount users, sorted by name, followed by the viewer who follow the other user,
+// and how many they are in total. Users banned in either direction by the viewer are excluded.
+func (db *appdbimpl) GetMutualFollows(viewer int64, other int64, amount int64) (UsersPage, DbError) {
+	var dbErr DbError
+	var page UsersPage
+
+	mutuals := fmt.Sprintf("FROM %[1]s AS Mine, %[1]s AS Theirs, %[2]s WHERE Mine.follower=? AND Theirs.follower=Mine.following "+
+		"AND Theirs.following=? AND User.id=Mine.following AND %[3]s", FollowTable, UserTable, notBannedWith("User.id"))
+	args := []interface{}{viewer, other, viewer, viewer}
+
+	err := db.c.QueryRow("SELECT count(*) "+mutuals, args...).Scan(&page.Total)
+	if err != nil {
+		dbErr.InternalError = err
+		return page, dbErr
+	}
+
+	rows, err := db.c.Query("SELECT User.id, User.name "+mutuals+" ORDER BY User.name LIMIT ?", append(args, amount)...)
+	if err != nil {
+		dbErr.InternalError = err
+		return page, dbErr
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var user User
+		err = rows.Scan(&user.Id, &user.Username)
+		if err != nil {
+			dbErr.InternalError = err
+			return page, dbErr
+		}
+		page.Users = append(page.Users, user)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		dbErr.InternalError = err
+	}
+
+	return page, dbErr
+}
+
+// GetFollowPath looks for the shortest chain of follows going from one user to the other, with a breadth first search
+// of at most maxDepth follows. Only the follows of users whose lists the first user can see are walked, and users
+// banned in either direction by the first one are skipped.
+// The path, both users included, is nil if none has been found. The returned bool is false if the search has been
+// stopped by the timeout before exploring every path within maxDepth.
+func (db *appdbimpl) GetFollowPath(from int64, to int64, maxDepth int, timeout time.Duration) ([]User, bool, DbError) {
+	var dbErr DbError
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// parents holds, for every reached user, the one it has been reached from
+	parents := map[int64]int64{from: from}
+	frontier := []int64{from}
+	found := from == to
+
+	for depth := 0; depth < maxDepth && len(frontier) > 0 && !found; depth++ {
+		var next []int64
+
+		for start := 0; start < len(frontier) && !found; start += followPathBatch {
+			end := start + followPathBatch
+			if end > len(frontier) {
+				end = len(frontier)
+			}
+			batch := frontier[start:end]
+
+			query := fmt.Sprintf("SELECT follower, following FROM %[1]s WHERE follower IN (%[2]s) AND %[3]s AND "+
+				"(follower=? OR EXISTS(SELECT * FROM %[4]s WHERE id=follower AND private=0) OR "+
+				"EXISTS(SELECT * FROM %[1]s AS Mine WHERE Mine.follower=? AND Mine.following=%[1]s.follower)) "+
+				"ORDER BY follower, following", FollowTable, strings.TrimSuffix(strings.Repeat("?, ", len(batch)), ", "),
+				notBannedWith("following"), UserTable)
+			var args []interface{}
+			for _, user := range batch {
+				args = append(args, user)
+			}
+			args = append(args, from, from, from, from)
+
+			rows, err := db.c.QueryContext(ctx, query, args...)
+			if err == nil {
+				for rows.Next() && !found {
+					var follower, following int64
+					err = rows.Scan(&follower, &following)
+					if err != nil {
+						break
+					}
+
+					if _, isReached := parents[following]; !isReached {
+						parents[following] = follower
+						next = append(next, following)
+						found = following == to
+					}
+				}
+
+				if err == nil {
+					err = rows.Err()
+				}
+				_ = rows.Close()
+			}
+
+			if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, false, dbErr
+			} else if err != nil {
+				dbErr.InternalError = err
+				return nil, false, dbErr
+			}
+		}
+
+		frontier = next
+	}
+
+	if !found {
+		return nil, true, dbErr
+	}
+
+	var ids []int64
+	for user := to; user != from; user = parents[user] {
+		ids = append([]int64{user}, ids...)
+	}
+	ids = append([]int64{from}, ids...)
+
+	path, dbErr := db.getUsersById(ids)
+	return path, true, dbErr
+}
+
+// getUsersById returns the users with the given ids, in the same order
+func (db *appdbimpl) getUsersById(ids []int64) ([]User, DbError) {
+	var dbErr DbError
+
+	var args []interface{}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	query := fmt.Sprintf("SELECT id, name FROM %s WHERE id IN (%s)", UserTable,
+		strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", "))
+	rows, err := db.c.Query(query, args...)
+	if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
+	}
+
+	defer rows.Close()
+
+	names := make(map[int64]string)
+	for rows.Next() {
+		var user User
+		err = rows.Scan(&user.Id, &user.Username)
+		if err != nil {
+			dbErr.InternalError = err
+			return nil, dbErr
+		}
+		names[user.Id] = user.Username
+	}
+
+	err = rows.Err()
+	if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
+	}
+
+	users := make([]User, 0, len(ids))
+	for _, id := range ids {
+		users = append(users, User{Id: id, Username: names[id]})
+	}
+
+	return users, dbErr
+}
@@ -0,0 +1,92 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+// pathNames returns the usernames of the path, in order
+func pathNames(path []User) []string {
+	names := make([]string, 0, len(path))
+	for _, user := range path {
+		names = append(names, user.Username)
+	}
+	return names
+}
+
+func TestGetFollowPath(t *testing.T) {
+	db, _ := newTestDatabase(t)
+	ids := createTestUsers(t, db, "a", "b", "c", "d", "e", "private", "f")
+	a, b, c, d, e, private, f := ids[0], ids[1], ids[2], ids[3], ids[4], ids[5], ids[6]
+
+	// a -> b -> c -> d, with a longer a -> e -> ... detour through a private account
+	follow(t, db, a, b)
+	follow(t, db, b, c)
+	follow(t, db, c, d)
+	follow(t, db, a, e)
+	follow(t, db, e, private)
+	follow(t, db, private, f)
+	if dbErr := db.SetUserPrivate(private, true); dbErr.InternalError != nil {
+		t.Fatalf("setting account private: %v", dbErr.InternalError)
+	}
+
+	tests := []struct {
+		name     string
+		from, to int64
+		maxDepth int
+		want     []string
+	}{
+		{"shortest", a, d, 6, []string{"a", "b", "c", "d"}},
+		{"same user", a, a, 6, []string{"a"}},
+		{"too deep", a, d, 2, nil},
+		{"followers are not walked", d, a, 6, nil},
+		{"private lists are not walked", a, f, 6, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path, complete, dbErr := db.GetFollowPath(test.from, test.to, test.maxDepth, time.Minute)
+			if dbErr.InternalError != nil || !complete {
+				t.Fatalf("searching path: %t, %v", complete, dbErr.InternalError)
+			}
+			got := pathNames(path)
+			if len(got) != len(test.want) {
+				t.Fatalf("got path %v, want %v", got, test.want)
+			}
+			for i := range got {
+				if got[i] != test.want[i] {
+					t.Fatalf("got path %v, want %v", got, test.want)
+				}
+			}
+		})
+	}
+
+	// Following the private account lets its list be walked
+	follow(t, db, a, private)
+	path, _, _ := db.GetFollowPath(a, f, 6, time.Minute)
+	if got := pathNames(path); len(got) != 3 || got[1] != "private" {
+		t.Errorf("got path %v, want it through the followed private account", got)
+	}
+
+	// Banned users are skipped
+	if _, dbErr := db.BanUser(a, c, false); dbErr.InternalError != nil {
+		t.Fatalf("banning user: %v", dbErr.InternalError)
+	}
+	path, _, _ = db.GetFollowPath(a, d, 6, time.Minute)
+	if path != nil {
+		t.Errorf("got path %v through a banned user", pathNames(path))
+	}
+}
+
+func TestGetFollowPathTimeout(t *testing.T) {
+	db, _ := newTestDatabase(t)
+	ids := createTestUsers(t, db, "a", "b", "c")
+	follow(t, db, ids[0], ids[1])
+	follow(t, db, ids[1], ids[2])
+
+	path, complete, dbErr := db.GetFollowPath(ids[0], ids[2], 6, time.Nanosecond)
+	if dbErr.InternalError != nil || complete || path != nil {
+		t.Errorf("got path %v, complete %t (%v), want an incomplete search", pathNames(path), complete,
+			dbErr.InternalError)
+	}
+}
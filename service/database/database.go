@@ -35,6 +35,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
+	"wasaphoto/service/geo"
 	"wasaphoto/service/utils"
 )
 
@@ -43,29 +45,94 @@ type AppDatabase interface {
 	Ping() error
 	GetUserId(string) (bool, int64, DbError)
 	DoesPhotoBelongToUser(int64, int64) bool
-	GetImage(int64, int64) ([]byte, DbError)
-	InsertPhoto([]byte, int64) DbError
+	GetImage(int64, int64, int64) ([]byte, DbError)
+	InsertPhoto([]byte, int64, string, time.Time, string) (int64, DbError)
+	SetPhotoAltText(int64, int64, string) (bool, DbError)
+	SetPhotoPlace(int64, int64, Place) (bool, DbError)
+	RemovePhotoPlace(int64, int64) (bool, DbError)
+	GetNearbyPhotos(int64, float64, float64, float64, int64) ([]Photo, DbError)
+	GetPlaceClusters(int64, geo.Box) ([]PlaceCluster, DbError)
 	EntityExists(int64, string) (bool, DbError)
 	ChangeUsername(int64, string) DbError
 	DeletePhoto(int64, int64) (bool, DbError)
-	GetUserProfile(int64, int64, int64) (UserProfile, DbError)
+	GetUserProfile(int64, int64, int64, int64) (UserProfile, DbError)
 	GetMyStream(int64, int64, int64) ([]Photo, DbError)
-	GetUserPhotos(int64, int64, int64) ([]Photo, DbError)
-	getProfileCounters(int64) (ProfileCounters, DbError)
+	GetUserPhotos(int64, int64, int64, int64) ([]Photo, DbError)
+	getProfileCounters(int64, int64) (ProfileCounters, DbError)
 	TargetUser(int64, int64, string) (bool, DbError)
+	BanUser(int64, int64, bool) (bool, DbError)
 	IsUserTargeted(int64, int64, string) (bool, DbError)
 	UntargetUser(int64, int64, string) (bool, DbError)
-	GetUsersList(int64, string) ([]User, DbError)
+	GetUsersList(int64, string, int64, int64) (UsersPage, DbError)
 	LikePhoto(int64, int64, int64) (bool, DbError)
 	UnlikePhoto(int64, int64, int64) (bool, DbError)
-	CommentPhoto(int64, int64, int64, string) (bool, DbError)
-	GetPhotoComments(int64, int64) ([]Comment, DbError)
-	DeleteComment(int64, int64, int64, int64) (bool, DbError)
+	CommentPhoto(int64, int64, int64, string, int64) (bool, DbError)
+	GetPhotoComments(int64, int64, int64, string) ([]Comment, DbError)
+	GetCommentReplies(int64, int64, int64, int64, int64) ([]Comment, DbError)
+	DoesCommentBelongToPhoto(int64, int64) bool
+	LikeComment(int64, int64, int64) (bool, DbError)
+	EditComment(int64, int64, int64, string, time.Time) (bool, DbError)
+	GetCommentRevisions(int64) ([]CommentRevision, DbError)
+	UnlikeComment(int64, int64, int64) (bool, DbError)
+	DeleteComment(int64, int64, int64, bool) (bool, DbError)
+	HideComment(int64, int64, int64, bool, bool) (bool, DbError)
 	DoSearch(string) ([]User, DbError)
+	DoesAlbumBelongToUser(int64, int64) bool
+	CreateAlbum(int64, string) (int64, DbError)
+	UpdateAlbum(int64, int64, string, int64) (bool, DbError)
+	DeleteAlbum(int64, int64) (bool, DbError)
+	GetAlbum(int64, int64, int64) (Album, DbError)
+	GetUserAlbums(int64, int64, int64, int64) ([]Album, DbError)
+	GetAlbumPhotos(int64, int64, int64, int64) ([]Photo, DbError)
+	AddPhotoToAlbum(int64, int64) (bool, DbError)
+	RemovePhotoFromAlbum(int64, int64) (bool, DbError)
+	ReorderAlbum(int64, []int64) (bool, DbError)
+	GetPhotoOwner(int64) (int64, DbError)
+	SavePhoto(int64, int64, string) DbError
+	UnsavePhoto(int64, int64) (bool, DbError)
+	GetSavedPhotos(int64, string, int64, int64) ([]Photo, DbError)
+	GetSavedCollections(int64) ([]SavedCollection, DbError)
+	ArchivePhoto(int64, int64) (bool, DbError)
+	RestorePhoto(int64, int64) (bool, DbError)
+	GetArchivedPhotos(int64, int64, int64) ([]Photo, DbError)
+	DoesStoryBelongToUser(int64, int64) bool
+	InsertStory([]byte, int64) (Story, DbError)
+	GetStoryImage(int64, int64) ([]byte, DbError)
+	MarkStorySeen(int64, int64) DbError
+	DeleteStory(int64, int64) (bool, DbError)
+	GetStoriesTray(int64) ([]StoryAuthor, DbError)
+	GetStoryViewers(int64, int64) ([]StoryViewer, DbError)
+	PurgeExpiredStories() (int64, DbError)
+	GetScheduledPhotos(int64, int64, int64) ([]Photo, DbError)
+	ReschedulePhoto(int64, int64, time.Time) (bool, DbError)
+	CancelScheduledPhoto(int64, int64) (bool, DbError)
+	PublishScheduledPhotos() (int64, DbError)
+	SetUserPrivate(int64, bool) DbError
+	IsUserPrivate(int64) (bool, DbError)
+	CanSeeUserContent(int64, int64) (bool, DbError)
+	RequestFollow(int64, int64) (bool, DbError)
+	GetFollowRequests(int64) ([]User, DbError)
+	ApproveFollowRequest(int64, int64) (bool, DbError)
+	DeleteFollowRequest(int64, int64) (bool, DbError)
+	GetRelationships(int64, []int64) ([]Relationship, DbError)
+	SetPhotoVisibility(int64, int64, string) (bool, DbError)
+	SetPhotoCommentPolicy(int64, int64, string) (bool, DbError)
+	GetCommentAccess(int64, int64) (int, DbError)
+	GetPhotoAccess(int64, int64, int64) (int, DbError)
+	GetFollowSuggestions(int64, int64) ([]Suggestion, DbError)
+	GetMutualFollows(int64, int64, int64) (UsersPage, DbError)
+	GetFollowPath(int64, int64, int, time.Duration) ([]User, bool, DbError)
+	ImportUsers(int64, string, []string) ([]ImportResult, DbError)
+	GetPhotoLikes(int64, int64, string, int64, int64) (LikesPage, DbError)
+	ReactToPhoto(int64, int64, string) (bool, DbError)
+	GetReactionsCounters(int64, int64) (map[string]int, DbError)
+	RepostPhoto(int64, int64) (bool, DbError)
+	UnrepostPhoto(int64, int64) (bool, DbError)
 }
 
 type UserProfile struct {
 	UserInfo    User
+	Private     bool
 	Photos      []Photo
 	ProfileInfo ProfileCounters
 }
@@ -77,22 +144,97 @@ type ProfileCounters struct {
 }
 
 type Comment struct {
+	Id           int64
+	Owner        User
+	Content      string
+	CreatedAt    string
+	ParentId     int64
+	ReplyCount   int
+	Deleted      bool
+	LikesCounter int
+	LikedByMe    bool
+	EditedAt     string
+	Hidden       bool
+}
+
+// CommentRevision is a version of a comment content, WrittenAt is when it was posted or written by an edit
+type CommentRevision struct {
+	Content   string
+	WrittenAt string
+}
+
+type Photo struct {
+	Id            int64
+	Owner         User
+	UploadedAt    string
+	AltText       string
+	Place         *Place
+	PublishAt     string
+	Visibility    string
+	CommentPolicy string
+	LikedByMe     bool
+	MyReaction    string
+	RepostedByMe  bool
+	RepostedBy    *User
+	RepostedAt    string
+	PhotoInfo     PhotoCounters
+}
+
+type Place struct {
+	Name      string
+	Latitude  float64
+	Longitude float64
+}
+
+type PlaceCluster struct {
+	Geohash    string
+	Latitude   float64
+	Longitude  float64
+	Count      int
+	CoverId    int64
+	CoverOwner User
+}
+
+type Album struct {
+	Id            int64
+	Owner         User
+	Name          string
+	Cover         int64
+	PhotosCounter int
+	CreatedAt     string
+}
+
+type SavedCollection struct {
+	Name          string
+	PhotosCounter int
+}
+
+type Story struct {
 	Id        int64
 	Owner     User
-	Content   string
 	CreatedAt string
+	ExpiresAt string
+	Seen      bool
 }
 
-type Photo struct {
-	Id         int64
-	Owner      User
-	UploadedAt string
-	PhotoInfo  PhotoCounters
+type StoryAuthor struct {
+	User    User
+	AllSeen bool
+	Stories []Story
+}
+
+type StoryViewer struct {
+	User   User
+	SeenAt string
 }
 
+// PhotoCounters holds the number of reactions, of every kind, of a photo in LikesCounter and the breakdown per reaction
+// in Reactions
 type PhotoCounters struct {
 	LikesCounter    int
 	CommentsCounter int
+	RepostsCounter  int
+	Reactions       map[string]int
 }
 
 type User struct {
@@ -100,6 +242,50 @@ type User struct {
 	Username string
 }
 
+type Relationship struct {
+	UserId     int64
+	Following  bool
+	FollowedBy bool
+	Banned     bool
+	Muted      bool
+	Requested  bool
+}
+
+// Suggestion is an account the user may want to follow, with the signals it has been ranked by. MutualFollow is the
+// name of one of the MutualFollows followed users who follow it.
+type Suggestion struct {
+	User          User
+	MutualFollows int
+	MutualFollow  string
+	Interactions  int
+	RecentPhotos  int
+}
+
+// Liker is a user who liked a photo, Followed tells if the viewer follows them
+type Liker struct {
+	User     User
+	Followed bool
+	Reaction string
+}
+
+type LikesPage struct {
+	Likers []Liker
+	Total  int
+}
+
+// ImportResult is the outcome of a row of an import
+type ImportResult struct {
+	Username string
+	UserId   int64
+	Status   string
+}
+
+type UsersPage struct {
+	Users      []User
+	Total      int
+	NextCursor int64
+}
+
 type DbError struct {
 	InternalError error
 	Code          int
@@ -124,12 +310,23 @@ func (e DbError) ToHttp() utils.HttpError {
 }
 
 const (
-	UserTable    string = "User"
-	PhotoTable   string = "Photo"
-	BanTable     string = "Ban"
-	LikeTable    string = "Like"
-	FollowTable  string = "Follow"
-	CommentTable string = "Comment"
+	UserTable            string = "User"
+	PhotoTable           string = "Photo"
+	BanTable             string = "Ban"
+	LikeTable            string = "Like"
+	FollowTable          string = "Follow"
+	CommentTable         string = "Comment"
+	AlbumTable           string = "Album"
+	AlbumPhotoTable      string = "AlbumPhoto"
+	BookmarkTable        string = "Bookmark"
+	StoryTable           string = "Story"
+	StoryViewTable       string = "StoryView"
+	FollowRequestTable   string = "FollowRequest"
+	MuteTable            string = "Mute"
+	CloseFriendTable     string = "CloseFriend"
+	CommentLikeTable     string = "CommentLike"
+	CommentRevisionTable string = "CommentRevision"
+	RepostTable          string = "Repost"
 )
 
 type appdbimpl struct {
@@ -142,6 +339,9 @@ var ParamsNameToTable = map[string]string{
 	"photo_id":         PhotoTable,
 	"targeted_user_id": UserTable,
 	"comment_id":       CommentTable,
+	"album_id":         AlbumTable,
+	"story_id":         StoryTable,
+	"other_id":         UserTable,
 }
 
 // New returns a new instance of AppDatabase based on the SQLite connection `db`.
@@ -160,8 +360,9 @@ func New(db *sql.DB) (AppDatabase, error) {
 		_, err := db.Exec(
 			` create table User
 					(
-						id   integer primary key autoincrement,
-						name text not null unique
+						id      integer primary key autoincrement,
+						name    text    not null unique,
+						private integer not null default 0
 					);
 
 				create table Ban
@@ -178,6 +379,28 @@ func New(db *sql.DB) (AppDatabase, error) {
 					primary key (follower, following)
 				);
 
+				create table Mute
+				(
+					muting integer not null references User on delete cascade,
+					muted  integer not null references User on delete cascade,
+					primary key (muting, muted)
+				);
+
+				create table CloseFriend
+				(
+					owner  integer not null references User on delete cascade,
+					friend integer not null references User on delete cascade,
+					primary key (owner, friend)
+				);
+
+				create table FollowRequest
+				(
+					requester  integer not null references User on delete cascade,
+					requested  integer not null references User on delete cascade,
+					created_at datetime default current_timestamp,
+					primary key (requester, requested)
+				);
+
 				create table Photo
 				(
 					id          integer
@@ -186,9 +409,24 @@ func New(db *sql.DB) (AppDatabase, error) {
 					references User
 					on delete cascade,
 					image       blob    not null,
-					uploaded_at datetime default current_timestamp
+					uploaded_at datetime default current_timestamp,
+					alt_text    text    not null default '',
+					place_name  text,
+					latitude    real,
+					longitude   real,
+					geohash     text,
+					archived_at datetime,
+					publish_at  datetime,
+					visibility  text    not null default 'public',
+					comment_policy text not null default 'everyone'
 				);
 
+				create index photo_geohash on Photo (geohash);
+
+				create index photo_publish_at on Photo (publish_at);
+
+				create index photo_uploaded_at on Photo (uploaded_at);
+
 				create table Comment
 				(
 					id         integer
@@ -200,9 +438,30 @@ func New(db *sql.DB) (AppDatabase, error) {
 					created_at datetime default current_timestamp not null,
 					photo      integer                            not null
 					references Photo
-					on delete cascade
+					on delete cascade,
+					parent     integer
+					references Comment
+					on delete cascade,
+					deleted_at datetime,
+					edited_at  datetime,
+					hidden_at  datetime
 				);
 
+				create index comment_parent on Comment (parent);
+
+				create table CommentRevision
+				(
+					id         integer
+					primary key autoincrement,
+					comment    integer  not null
+					references Comment
+					on delete cascade,
+					content    text     not null,
+					written_at datetime not null
+				);
+
+				create index comment_revision_comment on CommentRevision (comment);
+
 				create table Like
 				(
 					owner integer not null
@@ -211,13 +470,114 @@ func New(db *sql.DB) (AppDatabase, error) {
 					photo integer not null
 					references Photo
 					on delete cascade,
+					reaction text not null default 'heart',
 					primary key (owner, photo)
 				);
+
+				create table CommentLike
+				(
+					owner   integer not null
+					references User
+					on delete cascade,
+					comment integer not null
+					references Comment
+					on delete cascade,
+					primary key (owner, comment)
+				);
+
+				create table Repost
+				(
+					owner      integer                            not null
+					references User
+					on delete cascade,
+					photo      integer                            not null
+					references Photo
+					on delete cascade,
+					created_at datetime default current_timestamp not null,
+					primary key (owner, photo)
+				);
+
+				create table Album
+				(
+					id         integer
+					primary key autoincrement,
+					owner      integer                            not null
+					references User
+					on delete cascade,
+					name       text                               not null,
+					cover      integer
+					references Photo
+					on delete set null,
+					created_at datetime default current_timestamp not null
+				);
+
+				create table AlbumPhoto
+				(
+					album    integer not null
+					references Album
+					on delete cascade,
+					photo    integer not null
+					references Photo
+					on delete cascade,
+					position integer not null,
+					primary key (album, photo)
+				);
+
+				create table Bookmark
+				(
+					owner      integer                            not null
+					references User
+					on delete cascade,
+					photo      integer                            not null
+					references Photo
+					on delete cascade,
+					collection text     default ''                not null,
+					created_at datetime default current_timestamp not null,
+					primary key (owner, photo)
+				);
+
+				create table Story
+				(
+					id         integer
+					primary key autoincrement,
+					owner      integer  not null
+					references User
+					on delete cascade,
+					image      blob     not null,
+					created_at datetime not null,
+					expires_at datetime not null
+				);
+
+				create index story_expires_at on Story (expires_at);
+
+				create table StoryView
+				(
+					story   integer  not null
+					references Story
+					on delete cascade,
+					viewer  integer  not null
+					references User
+					on delete cascade,
+					seen_at datetime not null,
+					primary key (story, viewer)
+				);
 `)
 
 		if err != nil {
 			return nil, err
 		}
+
+		_, err = db.Exec(fmt.Sprintf("PRAGMA user_version = %d", len(migrations)))
+		if err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	} else {
+		err = migrate(db)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return &appdbimpl{
@@ -252,6 +612,10 @@ func (db *appdbimpl) IsUserTargeted(targetingUserId int64, targetedUserId int64,
 		query = fmt.Sprintf("SELECT count(*) FROM %s WHERE banned=? AND banning=?", BanTable)
 	case FollowTable:
 		query = fmt.Sprintf("SELECT count(*) FROM %s WHERE following=? AND follower=?", FollowTable)
+	case MuteTable:
+		query = fmt.Sprintf("SELECT count(*) FROM %s WHERE muted=? AND muting=?", MuteTable)
+	case CloseFriendTable:
+		query = fmt.Sprintf("SELECT count(*) FROM %s WHERE friend=? AND owner=?", CloseFriendTable)
 	default:
 		return false, dbErr
 	}
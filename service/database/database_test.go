@@ -0,0 +1,224 @@
+package database
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// newTestDatabase returns a database with the latest schema, stored in a temporary file removed after the test
+func newTestDatabase(t *testing.T) (*appdbimpl, *sql.DB) {
+	t.Helper()
+
+	conn := openTestConnection(t)
+	db, err := New(conn)
+	if err != nil {
+		t.Fatalf("creating database: %v", err)
+	}
+
+	return db.(*appdbimpl), conn
+}
+
+// openTestConnection opens an empty database in a temporary file, with foreign keys enforced on every connection
+func openTestConnection(t *testing.T) *sql.DB {
+	t.Helper()
+
+	conn, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "wasaphoto.db")+"?_foreign_keys=on")
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return conn
+}
+
+// createTestUsers creates a user for every name and returns their ids, in the same order
+func createTestUsers(t *testing.T, db *appdbimpl, names ...string) []int64 {
+	t.Helper()
+
+	ids := make([]int64, 0, len(names))
+	for _, name := range names {
+		_, id, dbErr := db.GetUserId(name)
+		if dbErr.InternalError != nil {
+			t.Fatalf("creating user %s: %v", name, dbErr.InternalError)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
+// insertTestPhoto uploads a photo of the owner, published now, with the given audience
+func insertTestPhoto(t *testing.T, db *appdbimpl, owner int64, visibility string) int64 {
+	t.Helper()
+
+	photo, dbErr := db.InsertPhoto([]byte("image"), owner, "", time.Time{}, visibility)
+	if dbErr.InternalError != nil {
+		t.Fatalf("inserting photo: %v", dbErr.InternalError)
+	}
+
+	return photo
+}
+
+// follow makes the follower follow the user, failing the test on errors
+func follow(t *testing.T, db *appdbimpl, follower int64, following int64) {
+	t.Helper()
+
+	_, dbErr := db.TargetUser(follower, following, FollowTable)
+	if dbErr.InternalError != nil {
+		t.Fatalf("following user: %v", dbErr.InternalError)
+	}
+}
+
+// baselineSchema is the schema of the databases created before migrations were introduced, at version 0
+const baselineSchema = `
+	create table User (id integer primary key autoincrement, name text not null unique);
+	create table Ban (banned integer not null references User on delete cascade,
+		banning integer not null references User on delete cascade, primary key (banned, banning));
+	create table Follow (follower integer not null references User on delete cascade,
+		following integer not null references User on delete cascade, primary key (follower, following));
+	create table Photo (id integer primary key autoincrement, owner integer not null references User on delete cascade,
+		image blob not null, uploaded_at datetime default current_timestamp);
+	create table Comment (id integer primary key autoincrement, owner integer not null references User on delete cascade,
+		content text not null, created_at datetime default current_timestamp not null,
+		photo integer not null references Photo on delete cascade);
+	create table Like (owner integer not null references User on delete cascade,
+		photo integer not null references Photo on delete cascade, primary key (owner, photo));
+`
+
+// tableColumns returns the columns, with their type, nullability and default, of every table of the database
+func tableColumns(t *testing.T, conn *sql.DB) map[string]string {
+	t.Helper()
+
+	rows, err := conn.Query("SELECT m.name || '.' || p.name, p.type || ' ' || p.\"notnull\" || ' ' || " +
+		"coalesce(p.dflt_value, '') FROM sqlite_master AS m, pragma_table_info(m.name) AS p WHERE m.type='table'")
+	if err != nil {
+		t.Fatalf("reading schema: %v", err)
+	}
+	defer rows.Close()
+
+	columns := make(map[string]string)
+	for rows.Next() {
+		var name, definition string
+		if err = rows.Scan(&name, &definition); err != nil {
+			t.Fatalf("reading schema: %v", err)
+		}
+		columns[name] = definition
+	}
+
+	return columns
+}
+
+func TestMigrationsUpgradeBaselineDatabase(t *testing.T) {
+	conn := openTestConnection(t)
+	_, err := conn.Exec(baselineSchema + `
+		insert into User (name) values ('alice'), ('bob');
+		insert into Photo (owner, image) values (1, x'00');
+		insert into Like (owner, photo) values (2, 1);
+		insert into Comment (owner, content, photo) values (2, 'nice', 1);`)
+	if err != nil {
+		t.Fatalf("creating baseline database: %v", err)
+	}
+
+	appDb, err := New(conn)
+	if err != nil {
+		t.Fatalf("migrating database: %v", err)
+	}
+	db := appDb.(*appdbimpl)
+
+	var version int
+	if err = conn.QueryRow("PRAGMA user_version").Scan(&version); err != nil || version != len(migrations) {
+		t.Fatalf("version is %d (%v), want %d", version, err, len(migrations))
+	}
+
+	_, fresh := newTestDatabase(t)
+	want, got := tableColumns(t, fresh), tableColumns(t, conn)
+	for column, definition := range want {
+		if got[column] != definition {
+			t.Errorf("column %s is %q after the migrations, %q in a new database", column, got[column], definition)
+		}
+	}
+	for column := range got {
+		if _, exists := want[column]; !exists {
+			t.Errorf("column %s exists only after the migrations", column)
+		}
+	}
+
+	// The old data is still readable with the new schema, and old likes are hearts
+	photos, dbErr := db.GetUserPhotos(1, 2, 10, 0)
+	if dbErr.InternalError != nil || len(photos) != 1 {
+		t.Fatalf("got %d photos (%v), want 1", len(photos), dbErr.InternalError)
+	}
+	if photos[0].MyReaction != HeartReaction || photos[0].PhotoInfo.CommentsCounter != 1 {
+		t.Errorf("got reaction %q and %d comments, want a heart and 1 comment", photos[0].MyReaction,
+			photos[0].PhotoInfo.CommentsCounter)
+	}
+
+	// Opening the database again doesn't apply the migrations twice
+	if _, err = New(conn); err != nil {
+		t.Errorf("opening migrated database: %v", err)
+	}
+}
+
+// commentTestPhoto comments the photo, replying to parent if it is not 0, and returns the id of the comment
+func commentTestPhoto(t *testing.T, db *appdbimpl, owner int64, photo int64, parent int64) int64 {
+	t.Helper()
+
+	ok, dbErr := db.CommentPhoto(owner, photo, 0, "comment", parent)
+	if dbErr.InternalError != nil || !ok {
+		t.Fatalf("commenting photo: %t, %v", ok, dbErr.InternalError)
+	}
+
+	var comment int64
+	if err := db.c.QueryRow("SELECT max(id) FROM Comment").Scan(&comment); err != nil {
+		t.Fatalf("reading comment id: %v", err)
+	}
+
+	return comment
+}
+
+// commentStates returns the comments of the photo still stored, mapped to whether they are deleted placeholders
+func commentStates(t *testing.T, db *appdbimpl, photo int64) map[int64]bool {
+	t.Helper()
+
+	rows, err := db.c.Query("SELECT id, deleted_at IS NOT NULL FROM Comment WHERE photo=?", photo)
+	if err != nil {
+		t.Fatalf("reading comments: %v", err)
+	}
+	defer rows.Close()
+
+	states := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		var deleted bool
+		if err = rows.Scan(&id, &deleted); err != nil {
+			t.Fatalf("reading comments: %v", err)
+		}
+		states[id] = deleted
+	}
+
+	return states
+}
+
+// assertCommentStates fails the test if the stored comments of the photo, and whether they are placeholders, differ
+// from the wanted ones
+func assertCommentStates(t *testing.T, db *appdbimpl, photo int64, want map[int64]bool) {
+	t.Helper()
+
+	got := commentStates(t, db, photo)
+	if len(got) != len(want) {
+		t.Errorf("got comments %v, want %v", got, want)
+		return
+	}
+	for id, deleted := range want {
+		if isDeleted, exists := got[id]; !exists || isDeleted != deleted {
+			t.Errorf("got comments %v, want %v", got, want)
+			return
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mattn/go-sqlite3"
+)
+
+// SetUserPrivate changes the account visibility. When the account becomes public, every pending follow request is
+// approved.
+func (db *appdbimpl) SetUserPrivate(user int64, private bool) DbError {
+	var dbErr DbError
+
+	tx, err := db.c.Begin()
+	if err != nil {
+		dbErr.InternalError = err
+		return dbErr
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET private=? WHERE id=?", UserTable)
+	_, err = tx.Exec(query, private, user)
+	if err == nil && !private {
+		query = fmt.Sprintf("INSERT OR IGNORE INTO %s (follower, following) SELECT requester, requested FROM %s "+
+			"WHERE requested=?", FollowTable, FollowRequestTable)
+		_, err = tx.Exec(query, user)
+		if err == nil {
+			query = fmt.Sprintf("DELETE FROM %s WHERE requested=?", FollowRequestTable)
+			_, err = tx.Exec(query, user)
+		}
+	}
+
+	if err == nil {
+		err = tx.Commit()
+	} else {
+		_ = tx.Rollback()
+	}
+
+	if err != nil {
+		dbErr.InternalError = err
+	}
+
+	return dbErr
+}
+
+func (db *appdbimpl) IsUserPrivate(user int64) (bool, DbError) {
+	var dbErr DbError
+	var private bool
+
+	query := fmt.Sprintf("SELECT private FROM %s WHERE id=?", UserTable)
+	err := db.c.QueryRow(query, user).Scan(&private)
+	if err != nil {
+		dbErr.InternalError = err
+	}
+
+	return private, dbErr
+}
+
+// CanSeeUserContent returns true if the viewer can see the photos of the owner: the account is public, the viewer is
+// the owner or an approved follower. Bans are not checked.
+func (db *appdbimpl) CanSeeUserContent(viewer int64, owner int64) (bool, DbError) {
+	var dbErr DbError
+	var count int
+
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE id=? AND (private=0 OR id=? OR "+
+		"EXISTS(SELECT * FROM %s WHERE follower=? AND following=%s.id))", UserTable, FollowTable, UserTable)
+	err := db.c.QueryRow(query, owner, viewer, viewer).Scan(&count)
+	if err != nil {
+		dbErr.InternalError = err
+	}
+
+	return count > 0, dbErr
+}
+
+// RequestFollow creates a pending follow request, a conflict is returned if the request has already been sent
+func (db *appdbimpl) RequestFollow(requester int64, requested int64) (bool, DbError) {
+	var dbErr DbError
+	var affected int64
+
+	query := fmt.Sprintf("INSERT INTO %s (requester, requested) VALUES (?, ?)", FollowRequestTable)
+	res, err := db.c.Exec(query, requester, requested)
+	if err != nil {
+		var sqlErr sqlite3.Error
+		dbErr.InternalError = err
+		if errors.As(err, &sqlErr) {
+			if errors.Is(sqlErr.ExtendedCode, sqlite3.ErrConstraintPrimaryKey) {
+				dbErr.Code = StateConflict
+			}
+		}
+		return false, dbErr
+	} else {
+		affected, _ = res.RowsAffected()
+	}
+
+	return affected > 0, dbErr
+}
+
+// GetFollowRequests returns the users waiting for the approval of the given one, oldest request first
+func (db *appdbimpl) GetFollowRequests(requested int64) ([]User, DbError) {
+	var dbErr DbError
+	var users []User
+
+	query := fmt.Sprintf("SELECT User.id, User.name FROM %s, %s WHERE %s.requester=User.id AND %s.requested=? "+
+		"ORDER BY %s.created_at, User.id", FollowRequestTable, UserTable, FollowRequestTable, FollowRequestTable,
+		FollowRequestTable)
+	rows, err := db.c.Query(query, requested)
+	if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var user User
+		err = rows.Scan(&user.Id, &user.Username)
+		if err != nil {
+			dbErr.InternalError = err
+			return nil, dbErr
+		}
+		users = append(users, user)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
+	}
+
+	return users, dbErr
+}
+
+// ApproveFollowRequest turns the pending request into a follow
+func (db *appdbimpl) ApproveFollowRequest(requested int64, requester int64) (bool, DbError) {
+	var dbErr DbError
+	var affected int64
+
+	tx, err := db.c.Begin()
+	if err != nil {
+		dbErr.InternalError = err
+		return false, dbErr
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE requester=? AND requested=?", FollowRequestTable)
+	res, err := tx.Exec(query, requester, requested)
+	if err == nil {
+		affected, _ = res.RowsAffected()
+		if affected > 0 {
+			query = fmt.Sprintf("INSERT OR IGNORE INTO %s (follower, following) VALUES (?, ?)", FollowTable)
+			_, err = tx.Exec(query, requester, requested)
+		}
+	}
+
+	if err == nil {
+		err = tx.Commit()
+	} else {
+		_ = tx.Rollback()
+	}
+
+	if err != nil {
+		dbErr.InternalError = err
+		return false, dbErr
+	}
+
+	return affected > 0, dbErr
+}
+
+// DeleteFollowRequest removes a pending request, either rejected by the requested user or withdrawn by the requester
+func (db *appdbimpl) DeleteFollowRequest(requested int64, requester int64) (bool, DbError) {
+	var dbErr DbError
+	var affected int64
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE requester=? AND requested=?", FollowRequestTable)
+	res, err := db.c.Exec(query, requester, requested)
+	if err != nil {
+		dbErr.InternalError = err
+		return false, dbErr
+	} else {
+		affected, _ = res.RowsAffected()
+	}
+
+	return affected > 0, dbErr
+}
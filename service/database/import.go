@@ -0,0 +1,102 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/mattn/go-sqlite3"
+)
+
+// Outcomes of a row of an import
+const (
+	ImportDone        string = "done"
+	ImportRequested   string = "requested"
+	ImportUnknownUser string = "unknownUser"
+	ImportConflict    string = "conflict"
+	ImportForbidden   string = "forbidden"
+)
+
+// ImportUsers follows, or bans, according to tableName, the users with the given usernames, in a single transaction.
+// Like a single follow, following a private account sends a follow request, and who banned the importing user can't be
+// followed. Every username gets its own outcome, rows failing for a known reason don't stop the import.
+func (db *appdbimpl) ImportUsers(owner int64, tableName string, usernames []string) ([]ImportResult, DbError) {
+	var dbErr DbError
+	var results []ImportResult
+
+	if tableName != FollowTable && tableName != BanTable {
+		return nil, dbErr
+	}
+
+	tx, err := db.c.Begin()
+	if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
+	}
+
+	query := fmt.Sprintf("SELECT id, private, EXISTS(SELECT * FROM %s WHERE banning=%s.id AND banned=?), "+
+		"EXISTS(SELECT * FROM %s WHERE follower=? AND following=%s.id) FROM %s WHERE name=?", BanTable, UserTable,
+		FollowTable, UserTable, UserTable)
+	for _, username := range usernames {
+		result := ImportResult{Username: username}
+		var isPrivate, isBanned, isFollowing bool
+		err = tx.QueryRow(query, owner, owner, username).Scan(&result.UserId, &isPrivate, &isBanned, &isFollowing)
+		if errors.Is(err, sql.ErrNoRows) {
+			err = nil
+			result.Status = ImportUnknownUser
+			results = append(results, result)
+			continue
+		} else if err != nil {
+			break
+		}
+
+		switch {
+		case result.UserId == owner || (tableName == FollowTable && isBanned):
+			result.Status = ImportForbidden
+		case tableName == FollowTable && isPrivate && !isFollowing:
+			result.Status, err = requestFollowInImport(tx, owner, result.UserId)
+		default:
+			var targetErr DbError
+			_, targetErr = targetUser(tx, owner, result.UserId, tableName)
+			if targetErr.Code == StateConflict {
+				result.Status = ImportConflict
+			} else if targetErr.InternalError != nil {
+				err = targetErr.InternalError
+			} else {
+				result.Status = ImportDone
+			}
+		}
+
+		if err != nil {
+			break
+		}
+		results = append(results, result)
+	}
+
+	if err == nil {
+		err = tx.Commit()
+	} else {
+		_ = tx.Rollback()
+	}
+
+	if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
+	}
+
+	return results, dbErr
+}
+
+// requestFollowInImport sends a follow request inside the transaction of an import, returning the outcome of the row
+func requestFollowInImport(tx *sql.Tx, requester int64, requested int64) (string, error) {
+	query := fmt.Sprintf("INSERT INTO %s (requester, requested) VALUES (?, ?)", FollowRequestTable)
+	_, err := tx.Exec(query, requester, requested)
+	if err != nil {
+		var sqlErr sqlite3.Error
+		if errors.As(err, &sqlErr) && errors.Is(sqlErr.ExtendedCode, sqlite3.ErrConstraintPrimaryKey) {
+			return ImportConflict, nil
+		}
+		return "", err
+	}
+
+	return ImportRequested, nil
+}
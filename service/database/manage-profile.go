@@ -5,26 +5,61 @@ import (
 	"errors"
 	"fmt"
 	"github.com/mattn/go-sqlite3"
+	"time"
 )
 
-func (db *appdbimpl) InsertPhoto(image []byte, ownerId int64) DbError {
-	// Upload the photo to the database
-	query := fmt.Sprintf("INSERT INTO %s (owner, image) VALUES (?, ?)", PhotoTable)
-	_, err := db.c.Exec(query, ownerId, image)
+// InsertPhoto uploads a photo, published immediately if publishAt is the zero time or scheduled otherwise
+func (db *appdbimpl) InsertPhoto(image []byte, ownerId int64, altText string, publishAt time.Time, visibility string) (int64, DbError) {
 	var dbErr DbError
+	var id int64
+
+	var publishAtValue sql.NullString
+	if !publishAt.IsZero() {
+		publishAtValue = sql.NullString{String: formatTimestamp(publishAt), Valid: true}
+	}
+
+	// Upload the photo to the database
+	query := fmt.Sprintf("INSERT INTO %s (owner, image, alt_text, publish_at, visibility) VALUES (?, ?, ?, ?, ?)", PhotoTable)
+	res, err := db.c.Exec(query, ownerId, image, altText, publishAtValue, visibility)
 	// If the insert was unsuccessful, return an error
 	if err != nil {
 		dbErr.InternalError = err
+		return id, dbErr
 	}
 
-	return dbErr
+	id, err = res.LastInsertId()
+	if err != nil {
+		dbErr.InternalError = err
+	}
+
+	return id, dbErr
 }
 
-// Photo has to belong to the user in path
-func (db *appdbimpl) GetImage(photo int64, user int64) ([]byte, DbError) {
+// Photo has to belong to the authenticated user
+func (db *appdbimpl) SetPhotoAltText(photo int64, user int64, altText string) (bool, DbError) {
+	var dbErr DbError
+	var affected int64
+
+	query := fmt.Sprintf("UPDATE %s SET alt_text=? WHERE id=? AND owner=?", PhotoTable)
+	res, err := db.c.Exec(query, altText, photo, user)
+	if err != nil {
+		dbErr.InternalError = err
+		return false, dbErr
+	} else {
+		affected, _ = res.RowsAffected()
+	}
+
+	return affected > 0, dbErr
+}
+
+// Photo has to belong to the user in path, archived photos are returned only to their owner and the others only to who
+// can see them
+func (db *appdbimpl) GetImage(photo int64, user int64, authUser int64) ([]byte, DbError) {
 	var image []byte
-	query := fmt.Sprintf("SELECT image FROM %s WHERE id=? AND owner=?", PhotoTable)
-	err := db.c.QueryRow(query, photo, user).Scan(&image)
+	query := fmt.Sprintf("SELECT image FROM %s WHERE id=? AND owner=? AND (%s OR owner=?) AND %s", PhotoTable, listedPhoto,
+		photoVisibleTo("?"))
+	args := []interface{}{photo, user, authUser}
+	err := db.c.QueryRow(query, append(args, viewerArgs(authUser)...)...).Scan(&image)
 	var dbErr DbError
 
 	if err != nil {
@@ -72,55 +107,111 @@ func (db *appdbimpl) DeletePhoto(photo int64, user int64) (bool, DbError) {
 	return affected > 0, dbErr
 }
 
-func (db *appdbimpl) GetUserProfile(id int64, photosAmount int64, photosOffset int64) (UserProfile, DbError) {
+// GetUserProfile returns the profile of the user with the photos visible to the viewer
+func (db *appdbimpl) GetUserProfile(id int64, viewer int64, photosAmount int64, photosOffset int64) (UserProfile, DbError) {
 	var up UserProfile
 	var dbErr DbError
 
 	up.UserInfo.Id = id
-	query := fmt.Sprintf("SELECT name FROM %s WHERE id=?", UserTable)
-	err := db.c.QueryRow(query, id).Scan(&up.UserInfo.Username)
+	query := fmt.Sprintf("SELECT name, private FROM %s WHERE id=?", UserTable)
+	err := db.c.QueryRow(query, id).Scan(&up.UserInfo.Username, &up.Private)
 
 	if err != nil {
 		dbErr.InternalError = err
 		return up, dbErr
 	}
 
-	up.Photos, dbErr = db.GetUserPhotos(id, photosAmount, photosOffset)
+	up.Photos, dbErr = db.GetUserPhotos(id, viewer, photosAmount, photosOffset)
 	if dbErr.InternalError != nil {
 		return up, dbErr
 	}
 
-	up.ProfileInfo, dbErr = db.getProfileCounters(id)
+	up.ProfileInfo, dbErr = db.getProfileCounters(id, viewer)
 
 	return up, dbErr
 }
 
-func (db *appdbimpl) GetUserPhotos(id int64, amount int64, offset int64) ([]Photo, DbError) {
+// GetUserPhotos returns the photos of the user visible to the viewer, newest first
+func (db *appdbimpl) GetUserPhotos(id int64, viewer int64, amount int64, offset int64) ([]Photo, DbError) {
 	var dbErr DbError
 	joinParam := UserTable + ".id"
-	userColumn := "name"
-	photoColumn := PhotoTable + ".id"
 
-	query := fmt.Sprintf("SELECT %s, %s, owner, uploaded_at FROM %s, %s WHERE owner=%s AND owner=? "+
-		"ORDER BY uploaded_at DESC LIMIT ? OFFSET ?", photoColumn, userColumn, PhotoTable, UserTable, joinParam)
-	rows, err := db.c.Query(query, id, amount, offset)
+	query := fmt.Sprintf("SELECT %s FROM %s, %s WHERE owner=%s AND owner=? AND %s AND %s "+
+		"ORDER BY uploaded_at DESC LIMIT ? OFFSET ?", photoColumns, PhotoTable, UserTable, joinParam, listedPhoto,
+		photoVisibleTo("?"))
+	args := append([]interface{}{id}, viewerArgs(viewer)...)
+	rows, err := db.c.Query(query, append(args, amount, offset)...)
 
 	if err != nil {
 		dbErr.InternalError = err
 		return nil, dbErr
 	}
 
+	defer rows.Close()
+
+	return db.scanPhotos(rows, viewer)
+}
+
+// photoColumns are the columns read by scanPhotos, in order. Queries using them must join Photo with User on the
+// photo owner.
+const photoColumns = "Photo.id, User.name, Photo.owner, Photo.uploaded_at, Photo.alt_text, Photo.place_name, " +
+	"Photo.latitude, Photo.longitude, Photo.publish_at, Photo.visibility, Photo.comment_policy"
+
+// listedPhoto is the condition selecting the photos shown in profiles, streams and every other list of photos.
+// Archived photos are only listed to their owner, in the archive, and so are scheduled photos until they are published.
+const listedPhoto = "Photo.archived_at IS NULL AND Photo.publish_at IS NULL"
+
+// scanPhotos reads every row selected with photoColumns, or with photoColumns followed by repostColumns, and fills in
+// the counters of each photo, and how the viewer reacted to it and whether they reposted it.
+func (db *appdbimpl) scanPhotos(rows *sql.Rows, viewer int64) ([]Photo, DbError) {
+	var dbErr DbError
 	var photos []Photo
-	var photo Photo
+
+	columns, err := rows.Columns()
+	if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
+	}
 
 	for rows.Next() {
-		err = rows.Scan(&photo.Id, &photo.Owner.Username, &photo.Owner.Id, &photo.UploadedAt)
+		var photo Photo
+		var placeName, publishAt sql.NullString
+		var latitude, longitude sql.NullFloat64
+		var reposterId sql.NullInt64
+		var reposterName, repostedAt sql.NullString
+		dest := []interface{}{&photo.Id, &photo.Owner.Username, &photo.Owner.Id, &photo.UploadedAt, &photo.AltText,
+			&placeName, &latitude, &longitude, &publishAt, &photo.Visibility, &photo.CommentPolicy}
+		if len(columns) > len(dest) {
+			dest = append(dest, &reposterId, &reposterName, &repostedAt)
+		}
+		err = rows.Scan(dest...)
 		if err != nil {
 			dbErr.InternalError = err
 			return nil, dbErr
 		}
 
-		photo.PhotoInfo, dbErr = db.getPhotoCounters(photo.Id)
+		if reposterId.Valid {
+			photo.RepostedBy = &User{Id: reposterId.Int64, Username: reposterName.String}
+			photo.RepostedAt = repostedAt.String
+		}
+
+		if placeName.Valid {
+			photo.Place = &Place{Name: placeName.String, Latitude: latitude.Float64, Longitude: longitude.Float64}
+		}
+		photo.PublishAt = publishAt.String
+
+		photo.PhotoInfo, dbErr = db.getPhotoCounters(photo.Id, viewer)
+		if dbErr.InternalError != nil {
+			return nil, dbErr
+		}
+
+		photo.MyReaction, dbErr = db.photoReactionOf(photo.Id, viewer)
+		if dbErr.InternalError != nil {
+			return nil, dbErr
+		}
+		photo.LikedByMe = photo.MyReaction != ""
+
+		photo.RepostedByMe, dbErr = db.isPhotoRepostedBy(photo.Id, viewer)
 		if dbErr.InternalError != nil {
 			return nil, dbErr
 		}
@@ -131,25 +222,34 @@ func (db *appdbimpl) GetUserPhotos(id int64, amount int64, offset int64) ([]Phot
 	err = rows.Err()
 	if err != nil {
 		dbErr.InternalError = err
+		return nil, dbErr
 	}
 
-	defer rows.Close()
-
 	return photos, dbErr
 }
 
-func (db *appdbimpl) getPhotoCounters(photoId int64) (PhotoCounters, DbError) {
+func (db *appdbimpl) getPhotoCounters(photoId int64, viewer int64) (PhotoCounters, DbError) {
 	var photoCounters PhotoCounters
 	var dbErr DbError
 
-	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE photo=?", LikeTable)
-	err := db.c.QueryRow(query, photoId).Scan(&photoCounters.LikesCounter)
+	photoCounters.Reactions, dbErr = db.GetReactionsCounters(photoId, viewer)
+	if dbErr.InternalError != nil {
+		return photoCounters, dbErr
+	}
+
+	for _, count := range photoCounters.Reactions {
+		photoCounters.LikesCounter += count
+	}
+
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE photo=?", RepostTable)
+	err := db.c.QueryRow(query, photoId).Scan(&photoCounters.RepostsCounter)
 	if err != nil {
 		dbErr.InternalError = err
 		return photoCounters, dbErr
 	}
 
-	query = fmt.Sprintf("SELECT count(*) FROM %s WHERE photo=?", CommentTable)
+	query = fmt.Sprintf("SELECT count(*) FROM %s WHERE photo=? AND deleted_at IS NULL AND hidden_at IS NULL",
+		CommentTable)
 	err = db.c.QueryRow(query, photoId).Scan(&photoCounters.CommentsCounter)
 	if err != nil {
 		dbErr.InternalError = err
@@ -158,7 +258,7 @@ func (db *appdbimpl) getPhotoCounters(photoId int64) (PhotoCounters, DbError) {
 	return photoCounters, dbErr
 }
 
-func (db *appdbimpl) getProfileCounters(id int64) (ProfileCounters, DbError) {
+func (db *appdbimpl) getProfileCounters(id int64, viewer int64) (ProfileCounters, DbError) {
 	var dbErr DbError
 	var profileCounters ProfileCounters
 
@@ -176,8 +276,9 @@ func (db *appdbimpl) getProfileCounters(id int64) (ProfileCounters, DbError) {
 		return profileCounters, dbErr
 	}
 
-	query = fmt.Sprintf("SELECT count(*) FROM %s WHERE owner=?", PhotoTable)
-	err = db.c.QueryRow(query, id).Scan(&profileCounters.PhotosCounter)
+	// Only the photos the viewer can see are counted
+	query = fmt.Sprintf("SELECT count(*) FROM %s WHERE owner=? AND %s AND %s", PhotoTable, listedPhoto, photoVisibleTo("?"))
+	err = db.c.QueryRow(query, append([]interface{}{id}, viewerArgs(viewer)...)...).Scan(&profileCounters.PhotosCounter)
 	if err != nil {
 		dbErr.InternalError = err
 	}
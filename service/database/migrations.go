@@ -0,0 +1,193 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// migrations upgrade the schema of the databases created by previous versions, the one at index i brings a database
+// from version i to version i+1. Databases created by New already have the latest schema and start from the last
+// version: every change to the schema in New needs a migration here too.
+var migrations = []string{
+	// Alt text of photos
+	`alter table Photo add column alt_text text not null default '';`,
+	// Places of photos
+	`alter table Photo add column place_name text;
+	alter table Photo add column latitude real;
+	alter table Photo add column longitude real;
+	alter table Photo add column geohash text;
+	create index photo_geohash on Photo (geohash);`,
+	// Albums
+	`create table Album
+	(
+		id         integer
+		primary key autoincrement,
+		owner      integer                            not null
+		references User
+		on delete cascade,
+		name       text                               not null,
+		cover      integer
+		references Photo
+		on delete set null,
+		created_at datetime default current_timestamp not null
+	);
+	create table AlbumPhoto
+	(
+		album    integer not null
+		references Album
+		on delete cascade,
+		photo    integer not null
+		references Photo
+		on delete cascade,
+		position integer not null,
+		primary key (album, photo)
+	);`,
+	// Bookmarks
+	`create table Bookmark
+	(
+		owner      integer                            not null
+		references User
+		on delete cascade,
+		photo      integer                            not null
+		references Photo
+		on delete cascade,
+		collection text     default ''                not null,
+		created_at datetime default current_timestamp not null,
+		primary key (owner, photo)
+	);`,
+	// Photo archive
+	`alter table Photo add column archived_at datetime;`,
+	// Stories
+	`create table Story
+	(
+		id         integer
+		primary key autoincrement,
+		owner      integer  not null
+		references User
+		on delete cascade,
+		image      blob     not null,
+		created_at datetime not null,
+		expires_at datetime not null
+	);
+	create index story_expires_at on Story (expires_at);
+	create table StoryView
+	(
+		story   integer  not null
+		references Story
+		on delete cascade,
+		viewer  integer  not null
+		references User
+		on delete cascade,
+		seen_at datetime not null,
+		primary key (story, viewer)
+	);`,
+	// Scheduled photos
+	`alter table Photo add column publish_at datetime;
+	create index photo_publish_at on Photo (publish_at);`,
+	// Private accounts and follow requests
+	`alter table User add column private integer not null default 0;
+	create table FollowRequest
+	(
+		requester  integer not null references User on delete cascade,
+		requested  integer not null references User on delete cascade,
+		created_at datetime default current_timestamp,
+		primary key (requester, requested)
+	);`,
+	// Muted users
+	`create table Mute
+	(
+		muting integer not null references User on delete cascade,
+		muted  integer not null references User on delete cascade,
+		primary key (muting, muted)
+	);`,
+	// Close friends and photo audiences
+	`create table CloseFriend
+	(
+		owner  integer not null references User on delete cascade,
+		friend integer not null references User on delete cascade,
+		primary key (owner, friend)
+	);
+	alter table Photo add column visibility text not null default 'public';`,
+	// Recent photos of follow suggestions
+	`create index photo_uploaded_at on Photo (uploaded_at);`,
+	// Comment replies
+	`alter table Comment add column parent integer references Comment on delete cascade;
+	alter table Comment add column deleted_at datetime;
+	create index comment_parent on Comment (parent);`,
+	// Comment likes
+	`create table CommentLike
+	(
+		owner   integer not null
+		references User
+		on delete cascade,
+		comment integer not null
+		references Comment
+		on delete cascade,
+		primary key (owner, comment)
+	);`,
+	// Comment revisions
+	`alter table Comment add column edited_at datetime;
+	create table CommentRevision
+	(
+		id         integer
+		primary key autoincrement,
+		comment    integer  not null
+		references Comment
+		on delete cascade,
+		content    text     not null,
+		written_at datetime not null
+	);
+	create index comment_revision_comment on CommentRevision (comment);`,
+	// Hidden comments
+	`alter table Comment add column hidden_at datetime;`,
+	// Comment policies
+	`alter table Photo add column comment_policy text not null default 'everyone';`,
+	// Reactions, the likes of previous versions are hearts
+	`alter table Like add column reaction text not null default 'heart';`,
+	// Reposts
+	`create table Repost
+	(
+		owner      integer                            not null
+		references User
+		on delete cascade,
+		photo      integer                            not null
+		references Photo
+		on delete cascade,
+		created_at datetime default current_timestamp not null,
+		primary key (owner, photo)
+	);`,
+}
+
+// migrate applies the migrations the database, versioned by pragma user_version, is missing. Every migration is applied
+// in its own transaction, together with the new version.
+func migrate(db *sql.DB) error {
+	var version int
+	err := db.QueryRow("PRAGMA user_version").Scan(&version)
+	if err != nil {
+		return err
+	}
+
+	for ; version < len(migrations); version++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(migrations[version])
+		if err == nil {
+			_, err = tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", version+1))
+		}
+
+		if err == nil {
+			err = tx.Commit()
+		} else {
+			_ = tx.Rollback()
+		}
+
+		if err != nil {
+			return fmt.Errorf("migrating database to version %d: %w", version+1, err)
+		}
+	}
+
+	return nil
+}
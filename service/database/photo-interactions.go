@@ -1,9 +1,10 @@
 package database
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
-	"github.com/mattn/go-sqlite3"
+	"wasaphoto/service/globaltime"
 )
 
 func (db *appdbimpl) DoesPhotoBelongToUser(userId int64, photo int64) bool {
@@ -24,23 +25,25 @@ func (db *appdbimpl) LikePhoto(authUser int64, photo int64, photoOwner int64) (b
 	var dbErr DbError
 	var affected int64
 
-	query := fmt.Sprintf("INSERT INTO %s (owner, photo) VALUES (?, ?)", LikeTable)
-	res, err := db.c.Exec(query, authUser, photo, photo, photoOwner)
+	// A like is a heart: any other reaction of the user is replaced, while liking twice is a conflict
+	query := fmt.Sprintf("INSERT INTO %s (owner, photo, reaction) VALUES (?, ?, ?) "+
+		"ON CONFLICT (owner, photo) DO UPDATE SET reaction=excluded.reaction WHERE reaction<>excluded.reaction", LikeTable)
+	res, err := db.c.Exec(query, authUser, photo, HeartReaction)
 
 	if err != nil {
-		var sqlErr sqlite3.Error
-		if errors.As(err, &sqlErr) {
-			if errors.Is(sqlErr.ExtendedCode, sqlite3.ErrConstraintPrimaryKey) {
-				dbErr.InternalError = err
-				dbErr.Code = StateConflict
-			}
-		}
+		dbErr.InternalError = err
 		return false, dbErr
 	} else {
 		affected, _ = res.RowsAffected()
 	}
 
-	return affected > 0, dbErr
+	if affected == 0 {
+		dbErr.InternalError = errors.New("photo already liked")
+		dbErr.Code = StateConflict
+		return false, dbErr
+	}
+
+	return true, dbErr
 }
 
 func (db *appdbimpl) UnlikePhoto(authUser int64, photo int64, photoOwner int64) (bool, DbError) {
@@ -60,12 +63,47 @@ func (db *appdbimpl) UnlikePhoto(authUser int64, photo int64, photoOwner int64)
 	return affected > 0, dbErr
 }
 
-func (db *appdbimpl) CommentPhoto(authUser int64, photo int64, photoOwner int64, commentText string) (bool, DbError) {
+// commentColumns are the columns read by scanComments, in order. Queries using them must join Comment with User on the
+// comment owner, and start their arguments with commentColumnsArgs.
+var commentColumns = fmt.Sprintf("%[1]s.id, %[1]s.owner, %[4]s.name, %[1]s.content, %[1]s.created_at, %[1]s.parent, "+
+	"%[1]s.deleted_at IS NOT NULL, (SELECT count(*) FROM %[1]s AS Reply WHERE Reply.parent=%[1]s.id AND "+
+	"(Reply.hidden_at IS NULL OR Reply.owner=? OR EXISTS(SELECT * FROM %[2]s WHERE %[2]s.id=%[1]s.photo AND "+
+	"%[2]s.owner=?))), "+
+	"(SELECT count(*) FROM %[3]s WHERE %[3]s.comment=%[1]s.id) AS likes, "+
+	"EXISTS(SELECT * FROM %[3]s WHERE %[3]s.comment=%[1]s.id AND %[3]s.owner=?), %[1]s.edited_at, "+
+	"%[1]s.hidden_at IS NOT NULL", CommentTable, PhotoTable, CommentLikeTable, UserTable)
+
+// commentColumnsArgs returns the arguments of commentColumns: hidden replies are counted, and likes are flagged, for
+// the viewer
+func commentColumnsArgs(viewer int64) []interface{} {
+	return []interface{}{viewer, viewer, viewer}
+}
+
+const (
+	CommentsOrderRecent string = "recent"
+	CommentsOrderTop    string = "top"
+)
+
+// CommentPhoto adds a comment to the photo, as a reply if parent is not 0. Replies go at most two levels
+// deep and only comments of the same photo, neither deleted nor hidden, can be replied to: false is returned otherwise.
+func (db *appdbimpl) CommentPhoto(authUser int64, photo int64, photoOwner int64, commentText string, parent int64) (bool, DbError) {
 	var dbErr DbError
 	var affected int64
 
-	query := fmt.Sprintf("INSERT INTO %s (owner, photo, content) VALUES (?, ?, ?)", CommentTable)
-	res, err := db.c.Exec(query, authUser, photo, commentText, photo, photoOwner)
+	var parentId sql.NullInt64
+	if parent != 0 {
+		parentId = sql.NullInt64{Int64: parent, Valid: true}
+	}
+
+	// With two levels of replies, the parent can have a parent of its own but not a grandparent. The creation time comes
+	// from globaltime, like the edit window it is compared with.
+	query := fmt.Sprintf("INSERT INTO %[1]s (owner, photo, content, parent, created_at) SELECT ?, ?, ?, ?, ? WHERE "+
+		"? IS NULL OR EXISTS(SELECT * FROM %[1]s AS Parent WHERE Parent.id=? AND Parent.photo=? AND "+
+		"Parent.deleted_at IS NULL AND Parent.hidden_at IS NULL AND "+
+		"(Parent.parent IS NULL OR EXISTS(SELECT * FROM %[1]s AS Grandparent WHERE Grandparent.id=Parent.parent AND "+
+		"Grandparent.parent IS NULL)))", CommentTable)
+	res, err := db.c.Exec(query, authUser, photo, commentText, parentId, formatTimestamp(globaltime.Now()), parentId,
+		parentId, photo)
 
 	if err != nil {
 		dbErr.InternalError = err
@@ -77,54 +115,174 @@ func (db *appdbimpl) CommentPhoto(authUser int64, photo int64, photoOwner int64,
 	return affected > 0, dbErr
 }
 
-func (db *appdbimpl) DeleteComment(photo int64, photoOwner int64, commentOwner int64, comment int64) (bool, DbError) {
+// DeleteComment deletes the comment if the user wrote it or owns the photo it is on. A comment with replies is kept as
+// a placeholder, without its content, so that the thread stays readable; placeholders left without replies are deleted
+// too. If banCommenter is true and the user is deleting someone else's comment, its author is banned too.
+func (db *appdbimpl) DeleteComment(photo int64, authUser int64, comment int64, banCommenter bool) (bool, DbError) {
 	var dbErr DbError
 	var affected int64
 
-	query := fmt.Sprintf("DELETE FROM %s WHERE id=? AND photo=? AND owner=?", CommentTable)
-	res, err := db.c.Exec(query, comment, photo, commentOwner)
-
+	tx, err := db.c.Begin()
 	if err != nil {
 		dbErr.InternalError = err
 		return false, dbErr
-	} else {
+	}
+
+	var replies int
+	var parent sql.NullInt64
+	query := fmt.Sprintf("SELECT (SELECT count(*) FROM %[1]s AS Reply WHERE Reply.parent=%[1]s.id), parent FROM %[1]s "+
+		"WHERE id=? AND photo=? AND deleted_at IS NULL AND (owner=? OR EXISTS(SELECT * FROM %[2]s WHERE %[2]s.id=? AND "+
+		"%[2]s.owner=?))", CommentTable, PhotoTable)
+	err = tx.QueryRow(query, comment, photo, authUser, photo, authUser).Scan(&replies, &parent)
+
+	// The author has to be banned before the comment, and its owner, disappear
+	if err == nil && banCommenter {
+		err = banCommentOwner(tx, authUser, comment)
+	}
+
+	var res sql.Result
+	if err == nil && replies > 0 {
+		query = fmt.Sprintf("UPDATE %s SET content='', deleted_at=?, edited_at=NULL WHERE id=?", CommentTable)
+		res, err = tx.Exec(query, formatTimestamp(globaltime.Now()), comment)
+		if err == nil {
+			query = fmt.Sprintf("DELETE FROM %s WHERE comment=?", CommentRevisionTable)
+			_, err = tx.Exec(query, comment)
+		}
+	} else if err == nil {
+		query = fmt.Sprintf("DELETE FROM %s WHERE id=?", CommentTable)
+		res, err = tx.Exec(query, comment)
+
+		// Going up the thread, remove the placeholders whose last reply has just been deleted
+		query = fmt.Sprintf("DELETE FROM %[1]s WHERE id=? AND deleted_at IS NOT NULL AND "+
+			"NOT EXISTS(SELECT * FROM %[1]s AS Reply WHERE Reply.parent=%[1]s.id) RETURNING parent", CommentTable)
+		for err == nil && parent.Valid {
+			err = tx.QueryRow(query, parent.Int64).Scan(&parent)
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			err = nil
+		}
+	} else if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+	}
+
+	if err == nil && res != nil {
 		affected, _ = res.RowsAffected()
 	}
 
+	if err == nil {
+		err = tx.Commit()
+	} else {
+		_ = tx.Rollback()
+	}
+
+	if err != nil {
+		dbErr.InternalError = err
+		return false, dbErr
+	}
+
 	return affected > 0, dbErr
 }
 
-func (db *appdbimpl) GetPhotoComments(photo int64, photoOwner int64) ([]Comment, DbError) {
+// commentsFilter returns the condition, and its arguments, hiding the comments hidden by the photo owner to everyone
+// but their author and the owner, and the comments of the users muted by the photo owner when the owner is the viewer
+func commentsFilter(photoOwner int64, viewer int64) (string, []interface{}) {
+	if viewer != photoOwner {
+		return fmt.Sprintf(" AND (%[1]s.hidden_at IS NULL OR %[1]s.owner=?)", CommentTable), []interface{}{viewer}
+	}
+
+	return fmt.Sprintf(" AND %s.owner NOT IN (SELECT muted FROM %s WHERE muting=?)", CommentTable, MuteTable),
+		[]interface{}{viewer}
+}
+
+// GetPhotoComments returns the top level comments of the photo, newest first or, with CommentsOrderTop, the most liked
+// first. Comments hidden by the photo owner are shown only to their author and to the owner, and comments of the users
+// muted by the photo owner are hidden when the owner is the viewer.
+func (db *appdbimpl) GetPhotoComments(photo int64, photoOwner int64, viewer int64, order string) ([]Comment, DbError) {
 	var dbErr DbError
-	var comments []Comment
 
-	joinParam := UserTable + ".id"
-	userColumn := "name"
-	commentColumn := CommentTable + ".id"
-	query := fmt.Sprintf("SELECT %s, owner, %s, content, created_at FROM %s, %s WHERE owner=%s AND photo=?"+
-		" AND EXISTS(SELECT * FROM %s WHERE id=? AND owner=?) ORDER BY created_at DESC ", commentColumn, userColumn, CommentTable, UserTable, joinParam, PhotoTable)
-	rows, err := db.c.Query(query, photo, photo, photoOwner)
+	orderBy := "created_at DESC"
+	if order == CommentsOrderTop {
+		orderBy = "likes DESC, " + orderBy
+	}
 
+	filter, filterArgs := commentsFilter(photoOwner, viewer)
+	query := fmt.Sprintf("SELECT %s FROM %s, %s WHERE %s.owner=User.id AND photo=? AND parent IS NULL"+
+		" AND EXISTS(SELECT * FROM %s WHERE id=? AND owner=?)%s ORDER BY %s", commentColumns, CommentTable,
+		UserTable, CommentTable, PhotoTable, filter, orderBy)
+	args := append(commentColumnsArgs(viewer), photo, photo, photoOwner)
+	rows, err := db.c.Query(query, append(args, filterArgs...)...)
 	if err != nil {
 		dbErr.InternalError = err
-		return comments, dbErr
-	} else {
-		for rows.Next() {
-			var comment Comment
-			err := rows.Scan(&comment.Id, &comment.Owner.Id, &comment.Owner.Username, &comment.Content, &comment.CreatedAt)
-			if err != nil {
-				dbErr.InternalError = err
-				break
-			}
-			comments = append(comments, comment)
-		}
+		return nil, dbErr
+	}
+
+	defer rows.Close()
+
+	return scanComments(rows)
+}
+
+// GetCommentReplies returns a page of the direct replies to the comment, oldest first, filtered like GetPhotoComments
+func (db *appdbimpl) GetCommentReplies(comment int64, photoOwner int64, viewer int64, amount int64, offset int64) ([]Comment, DbError) {
+	var dbErr DbError
+
+	filter, filterArgs := commentsFilter(photoOwner, viewer)
+	query := fmt.Sprintf("SELECT %s FROM %s, %s WHERE %s.owner=User.id AND parent=?%s ORDER BY created_at, %s.id "+
+		"LIMIT ? OFFSET ?", commentColumns, CommentTable, UserTable, CommentTable, filter, CommentTable)
+	args := append(append(commentColumnsArgs(viewer), comment), filterArgs...)
+	rows, err := db.c.Query(query, append(args, amount, offset)...)
+	if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
+	}
+
+	defer rows.Close()
+
+	return scanComments(rows)
+}
+
+// DoesCommentBelongToPhoto returns true if the comment, deleted or not, is on the photo
+func (db *appdbimpl) DoesCommentBelongToPhoto(photo int64, comment int64) bool {
+	var count int
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE id=? AND photo=?", CommentTable)
+	err := db.c.QueryRow(query, comment, photo).Scan(&count)
+
+	if err != nil {
+		return false
+	}
+
+	return count > 0
+}
 
-		err = rows.Err()
+// scanComments reads every row selected with commentColumns. The owner and the content of deleted comments are
+// cleared.
+func scanComments(rows *sql.Rows) ([]Comment, DbError) {
+	var dbErr DbError
+	var comments []Comment
+
+	for rows.Next() {
+		var comment Comment
+		var parent sql.NullInt64
+		var editedAt sql.NullString
+		err := rows.Scan(&comment.Id, &comment.Owner.Id, &comment.Owner.Username, &comment.Content, &comment.CreatedAt,
+			&parent, &comment.Deleted, &comment.ReplyCount, &comment.LikesCounter, &comment.LikedByMe, &editedAt,
+			&comment.Hidden)
 		if err != nil {
 			dbErr.InternalError = err
+			return nil, dbErr
 		}
 
-		defer rows.Close()
+		comment.ParentId = parent.Int64
+		comment.EditedAt = editedAt.String
+		if comment.Deleted {
+			comment.Owner = User{}
+		}
+		comments = append(comments, comment)
+	}
+
+	err := rows.Err()
+	if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
 	}
 
 	return comments, dbErr
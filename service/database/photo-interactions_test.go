@@ -0,0 +1,69 @@
+package database
+
+import (
+	"testing"
+)
+
+func TestDeleteCommentPlaceholders(t *testing.T) {
+	db, _ := newTestDatabase(t)
+	ids := createTestUsers(t, db, "owner", "alice", "bob")
+	owner, alice, bob := ids[0], ids[1], ids[2]
+	photo := insertTestPhoto(t, db, owner, PublicVisibility)
+
+	deleteComment := func(user int64, comment int64) {
+		t.Helper()
+		ok, dbErr := db.DeleteComment(photo, user, comment, false)
+		if dbErr.InternalError != nil || !ok {
+			t.Fatalf("deleting comment %d: %t, %v", comment, ok, dbErr.InternalError)
+		}
+	}
+
+	comment := commentTestPhoto(t, db, alice, photo, 0)
+	reply := commentTestPhoto(t, db, bob, photo, comment)
+	nested := commentTestPhoto(t, db, alice, photo, reply)
+
+	// A comment with replies becomes a placeholder
+	deleteComment(alice, comment)
+	assertCommentStates(t, db, photo, map[int64]bool{comment: true, reply: false, nested: false})
+
+	// Placeholders can't be deleted again
+	ok, dbErr := db.DeleteComment(photo, alice, comment, false)
+	if dbErr.InternalError != nil || ok {
+		t.Errorf("deleting placeholder: %t, %v", ok, dbErr.InternalError)
+	}
+
+	// The photo owner can delete the replies of others, the reply with a reply becomes a placeholder too
+	deleteComment(owner, reply)
+	assertCommentStates(t, db, photo, map[int64]bool{comment: true, reply: true, nested: false})
+
+	// Deleting the last reply removes the placeholders above it, up to the top of the thread
+	deleteComment(alice, nested)
+	assertCommentStates(t, db, photo, map[int64]bool{})
+}
+
+func TestDeleteCommentKeepsRepliedPlaceholders(t *testing.T) {
+	db, _ := newTestDatabase(t)
+	ids := createTestUsers(t, db, "owner", "alice", "bob")
+	owner, alice, bob := ids[0], ids[1], ids[2]
+	photo := insertTestPhoto(t, db, owner, PublicVisibility)
+
+	comment := commentTestPhoto(t, db, alice, photo, 0)
+	first := commentTestPhoto(t, db, bob, photo, comment)
+	second := commentTestPhoto(t, db, bob, photo, comment)
+
+	if _, dbErr := db.DeleteComment(photo, alice, comment, false); dbErr.InternalError != nil {
+		t.Fatalf("deleting comment: %v", dbErr.InternalError)
+	}
+	if _, dbErr := db.DeleteComment(photo, bob, first, false); dbErr.InternalError != nil {
+		t.Fatalf("deleting reply: %v", dbErr.InternalError)
+	}
+
+	// The placeholder still has a reply
+	assertCommentStates(t, db, photo, map[int64]bool{comment: true, second: false})
+
+	// Others can't delete comments on photos they don't own
+	ok, dbErr := db.DeleteComment(photo, alice, second, false)
+	if dbErr.InternalError != nil || ok {
+		t.Errorf("deleting someone else's reply: %t, %v", ok, dbErr.InternalError)
+	}
+}
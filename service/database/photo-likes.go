@@ -0,0 +1,50 @@
+package database
+
+import (
+	"fmt"
+)
+
+// GetPhotoLikes returns a page of the users who reacted to the photo, with the given reaction or any if it is empty:
+// the ones the viewer follows first, then the others, latest reaction first. Users banned by, or who banned, the viewer
+// are excluded.
+func (db *appdbimpl) GetPhotoLikes(photo int64, viewer int64, reaction string, amount int64, offset int64) (LikesPage, DbError) {
+	var dbErr DbError
+	var page LikesPage
+
+	likers := fmt.Sprintf("FROM %s, %s WHERE %s.owner=User.id AND %s.photo=? AND (?='' OR %s.reaction=?) AND %s",
+		LikeTable, UserTable, LikeTable, LikeTable, LikeTable, notBannedWith("User.id"))
+	args := []interface{}{photo, reaction, reaction, viewer, viewer}
+
+	err := db.c.QueryRow("SELECT count(*) "+likers, args...).Scan(&page.Total)
+	if err != nil {
+		dbErr.InternalError = err
+		return page, dbErr
+	}
+
+	query := fmt.Sprintf("SELECT User.id, User.name, EXISTS(SELECT * FROM %s WHERE follower=? AND following=User.id) AS followed, "+
+		"%s.reaction %s ORDER BY followed DESC, %s.rowid DESC LIMIT ? OFFSET ?", FollowTable, LikeTable, likers, LikeTable)
+	rows, err := db.c.Query(query, append(append([]interface{}{viewer}, args...), amount, offset)...)
+	if err != nil {
+		dbErr.InternalError = err
+		return page, dbErr
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var liker Liker
+		err = rows.Scan(&liker.User.Id, &liker.User.Username, &liker.Followed, &liker.Reaction)
+		if err != nil {
+			dbErr.InternalError = err
+			return page, dbErr
+		}
+		page.Likers = append(page.Likers, liker)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		dbErr.InternalError = err
+	}
+
+	return page, dbErr
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Reactions users can pick for a photo, a like is a heart
+const (
+	HeartReaction string = "heart"
+	LaughReaction string = "laugh"
+	WowReaction   string = "wow"
+	SadReaction   string = "sad"
+	FireReaction  string = "fire"
+	ClapReaction  string = "clap"
+)
+
+// Reactions is the set of reactions users can pick from, in the order they are shown
+var Reactions = []string{HeartReaction, LaughReaction, WowReaction, SadReaction, FireReaction, ClapReaction}
+
+// IsReaction returns true if the reaction is in Reactions
+func IsReaction(reaction string) bool {
+	for _, r := range Reactions {
+		if r == reaction {
+			return true
+		}
+	}
+	return false
+}
+
+// ReactToPhoto sets the reaction of the user to the photo, replacing the previous one: users react at most once per
+// photo
+func (db *appdbimpl) ReactToPhoto(authUser int64, photo int64, reaction string) (bool, DbError) {
+	var dbErr DbError
+	var affected int64
+
+	query := fmt.Sprintf("INSERT INTO %s (owner, photo, reaction) VALUES (?, ?, ?) "+
+		"ON CONFLICT (owner, photo) DO UPDATE SET reaction=excluded.reaction", LikeTable)
+	res, err := db.c.Exec(query, authUser, photo, reaction)
+
+	if err != nil {
+		dbErr.InternalError = err
+		return false, dbErr
+	} else {
+		affected, _ = res.RowsAffected()
+	}
+
+	return affected > 0, dbErr
+}
+
+// photoReactionOf returns the reaction of the user to the photo, empty if they didn't react
+func (db *appdbimpl) photoReactionOf(photo int64, user int64) (string, DbError) {
+	var dbErr DbError
+	var reaction string
+
+	query := fmt.Sprintf("SELECT reaction FROM %s WHERE photo=? AND owner=?", LikeTable)
+	err := db.c.QueryRow(query, photo, user).Scan(&reaction)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		dbErr.InternalError = err
+	}
+
+	return reaction, dbErr
+}
+
+// GetReactionsCounters returns how many users reacted to the photo with each reaction, every reaction in Reactions is
+// present. Like the list of the users who reacted, users banned by, or who banned, the viewer are not counted.
+func (db *appdbimpl) GetReactionsCounters(photo int64, viewer int64) (map[string]int, DbError) {
+	var dbErr DbError
+
+	counters := make(map[string]int, len(Reactions))
+	for _, reaction := range Reactions {
+		counters[reaction] = 0
+	}
+
+	query := fmt.Sprintf("SELECT reaction, count(*) FROM %s WHERE photo=? AND %s GROUP BY reaction", LikeTable,
+		notBannedWith("owner"))
+	rows, err := db.c.Query(query, photo, viewer, viewer)
+	if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var reaction string
+		var count int
+		err = rows.Scan(&reaction, &count)
+		if err != nil {
+			dbErr.InternalError = err
+			return nil, dbErr
+		}
+		counters[reaction] = count
+	}
+
+	err = rows.Err()
+	if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
+	}
+
+	return counters, dbErr
+}
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"fmt"
+)
+
+// Audiences of a photo
+const (
+	PublicVisibility       string = "public"
+	FollowersVisibility    string = "followers"
+	CloseFriendsVisibility string = "closeFriends"
+	PrivateVisibility      string = "private"
+)
+
+// Results of the access check of a photo
+const (
+	PhotoAccessGranted = iota
+	PhotoNotOwned
+	PhotoOwnerBanned
+	PhotoAccountPrivate
+	PhotoRestricted
+)
+
+// photoVisibleTo returns the condition selecting the photos the viewer can see: their own photos, or the ones of who
+// didn't ban them whose audience includes them. Public photos of private accounts are seen only by approved followers.
+// The viewer is an SQL expression: a column, or a parameter placeholder bound with viewerArgs.
+func photoVisibleTo(viewer string) string {
+	return fmt.Sprintf("(Photo.owner=%[1]s OR (NOT EXISTS(SELECT * FROM %[2]s WHERE banning=Photo.owner AND banned=%[1]s) AND "+
+		"(Photo.visibility='%[5]s' AND NOT EXISTS(SELECT * FROM %[3]s AS Owner WHERE Owner.id=Photo.owner AND Owner.private=1) OR "+
+		"Photo.visibility IN ('%[5]s', '%[6]s') AND EXISTS(SELECT * FROM %[4]s WHERE follower=%[1]s AND following=Photo.owner) OR "+
+		"Photo.visibility='%[7]s' AND EXISTS(SELECT * FROM %[8]s WHERE owner=Photo.owner AND friend=%[1]s))))",
+		viewer, BanTable, UserTable, FollowTable, PublicVisibility, FollowersVisibility, CloseFriendsVisibility,
+		CloseFriendTable)
+}
+
+// viewerArgs returns the arguments binding the placeholders of photoVisibleTo("?")
+func viewerArgs(viewer int64) []interface{} {
+	return []interface{}{viewer, viewer, viewer, viewer}
+}
+
+// Photo has to belong to the authenticated user
+func (db *appdbimpl) SetPhotoVisibility(photo int64, user int64, visibility string) (bool, DbError) {
+	var dbErr DbError
+	var affected int64
+
+	query := fmt.Sprintf("UPDATE %s SET visibility=? WHERE id=? AND owner=?", PhotoTable)
+	res, err := db.c.Exec(query, visibility, photo, user)
+	if err != nil {
+		dbErr.InternalError = err
+		return false, dbErr
+	} else {
+		affected, _ = res.RowsAffected()
+	}
+
+	return affected > 0, dbErr
+}
+
+// GetPhotoAccess tells whether the viewer can see the photo of the user in path and, if not, why. Photos not listed
+// are considered not owned by the user unless the viewer is their owner. Access is granted exactly when photoVisibleTo
+// lets the photo through, so that single photos and lists agree; the other checks only pick the refusal reason.
+func (db *appdbimpl) GetPhotoAccess(photo int64, owner int64, viewer int64) (int, DbError) {
+	var dbErr DbError
+	var isOwned, isBanned, canSeeAccount, isVisible bool
+
+	query := fmt.Sprintf("SELECT EXISTS(SELECT * FROM %[1]s WHERE id=? AND owner=? AND (%[2]s OR owner=?)), "+
+		"EXISTS(SELECT * FROM %[3]s WHERE banning=? AND banned=?), "+
+		"EXISTS(SELECT * FROM %[4]s WHERE id=? AND (private=0 OR id=? OR EXISTS(SELECT * FROM %[5]s WHERE follower=? AND following=%[4]s.id))), "+
+		"EXISTS(SELECT * FROM %[1]s WHERE id=? AND %[6]s)", PhotoTable, listedPhoto, BanTable, UserTable, FollowTable,
+		photoVisibleTo("?"))
+	args := []interface{}{photo, owner, viewer, owner, viewer, owner, viewer, viewer, photo}
+	err := db.c.QueryRow(query, append(args, viewerArgs(viewer)...)...).Scan(&isOwned, &isBanned, &canSeeAccount, &isVisible)
+	if err != nil {
+		dbErr.InternalError = err
+		return PhotoRestricted, dbErr
+	}
+
+	switch {
+	case !isOwned:
+		return PhotoNotOwned, dbErr
+	case isVisible:
+		return PhotoAccessGranted, dbErr
+	case isBanned && owner != viewer:
+		return PhotoOwnerBanned, dbErr
+	case !canSeeAccount:
+		return PhotoAccountPrivate, dbErr
+	}
+
+	return PhotoRestricted, dbErr
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"testing"
+)
+
+// TestPhotoAccessMatchesLists checks, for every audience and relationship, the outcome of GetPhotoAccess and that it
+// grants access exactly to the photos listed in the owner profile
+func TestPhotoAccessMatchesLists(t *testing.T) {
+	db, _ := newTestDatabase(t)
+	ids := createTestUsers(t, db, "owner", "private", "follower", "friend", "stranger", "banned")
+	owner, private, follower, friend, stranger, banned := ids[0], ids[1], ids[2], ids[3], ids[4], ids[5]
+
+	if dbErr := db.SetUserPrivate(private, true); dbErr.InternalError != nil {
+		t.Fatalf("setting account private: %v", dbErr.InternalError)
+	}
+	for _, account := range []int64{owner, private} {
+		follow(t, db, follower, account)
+		if _, dbErr := db.TargetUser(account, friend, CloseFriendTable); dbErr.InternalError != nil {
+			t.Fatalf("adding close friend: %v", dbErr.InternalError)
+		}
+	}
+	if _, dbErr := db.BanUser(owner, banned, false); dbErr.InternalError != nil {
+		t.Fatalf("banning user: %v", dbErr.InternalError)
+	}
+
+	viewers := map[string]int64{"owner": 0, "follower": follower, "friend": friend, "stranger": stranger, "banned": banned}
+	tests := []struct {
+		account    int64
+		visibility string
+		want       map[string]int
+	}{
+		{owner, PublicVisibility, map[string]int{"owner": PhotoAccessGranted, "follower": PhotoAccessGranted,
+			"friend": PhotoAccessGranted, "stranger": PhotoAccessGranted, "banned": PhotoOwnerBanned}},
+		{owner, FollowersVisibility, map[string]int{"owner": PhotoAccessGranted, "follower": PhotoAccessGranted,
+			"friend": PhotoRestricted, "stranger": PhotoRestricted, "banned": PhotoOwnerBanned}},
+		{owner, CloseFriendsVisibility, map[string]int{"owner": PhotoAccessGranted, "follower": PhotoRestricted,
+			"friend": PhotoAccessGranted, "stranger": PhotoRestricted, "banned": PhotoOwnerBanned}},
+		{owner, PrivateVisibility, map[string]int{"owner": PhotoAccessGranted, "follower": PhotoRestricted,
+			"friend": PhotoRestricted, "stranger": PhotoRestricted, "banned": PhotoOwnerBanned}},
+		{private, PublicVisibility, map[string]int{"owner": PhotoAccessGranted, "follower": PhotoAccessGranted,
+			"friend": PhotoAccountPrivate, "stranger": PhotoAccountPrivate, "banned": PhotoAccountPrivate}},
+		{private, FollowersVisibility, map[string]int{"owner": PhotoAccessGranted, "follower": PhotoAccessGranted,
+			"friend": PhotoAccountPrivate, "stranger": PhotoAccountPrivate, "banned": PhotoAccountPrivate}},
+		{private, CloseFriendsVisibility, map[string]int{"owner": PhotoAccessGranted, "follower": PhotoRestricted,
+			"friend": PhotoAccessGranted, "stranger": PhotoAccountPrivate, "banned": PhotoAccountPrivate}},
+		{private, PrivateVisibility, map[string]int{"owner": PhotoAccessGranted, "follower": PhotoRestricted,
+			"friend": PhotoAccountPrivate, "stranger": PhotoAccountPrivate, "banned": PhotoAccountPrivate}},
+	}
+
+	for _, test := range tests {
+		photo := insertTestPhoto(t, db, test.account, test.visibility)
+
+		for name, viewer := range viewers {
+			if viewer == 0 {
+				viewer = test.account
+			}
+
+			access, dbErr := db.GetPhotoAccess(photo, test.account, viewer)
+			if dbErr.InternalError != nil {
+				t.Fatalf("checking access: %v", dbErr.InternalError)
+			}
+			if access != test.want[name] {
+				t.Errorf("%s photo of account %d, viewed by %s: access is %d, want %d", test.visibility, test.account,
+					name, access, test.want[name])
+			}
+
+			photos, dbErr := db.GetUserPhotos(test.account, viewer, 100, 0)
+			if dbErr.InternalError != nil {
+				t.Fatalf("listing photos: %v", dbErr.InternalError)
+			}
+			listed := false
+			for _, p := range photos {
+				listed = listed || p.Id == photo
+			}
+			if listed != (access == PhotoAccessGranted) {
+				t.Errorf("%s photo of account %d, viewed by %s: listed is %t, access is %d", test.visibility,
+					test.account, name, listed, access)
+			}
+		}
+	}
+}
+
+func TestPhotoAccessOfPhotoNotOwned(t *testing.T) {
+	db, _ := newTestDatabase(t)
+	ids := createTestUsers(t, db, "owner", "other")
+
+	photo := insertTestPhoto(t, db, ids[0], PublicVisibility)
+	access, dbErr := db.GetPhotoAccess(photo, ids[1], ids[1])
+	if dbErr.InternalError != nil || access != PhotoNotOwned {
+		t.Errorf("access is %d (%v), want %d", access, dbErr.InternalError, PhotoNotOwned)
+	}
+}
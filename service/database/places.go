@@ -0,0 +1,168 @@
+package database
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"wasaphoto/service/geo"
+)
+
+// maxCoverCells is the maximum number of geohash cells used to look for the photos inside an area
+const maxCoverCells = 16
+
+// nearbyCandidatesFactor is how many photos, for every one requested, are read from the database before the exact
+// distance sort. The approximate ordering of the query only needs a margin, not every photo of the area.
+const nearbyCandidatesFactor = 4
+
+// Photo has to belong to the authenticated user.
+// The place is always the one chosen by the owner: it is never read from the image metadata.
+func (db *appdbimpl) SetPhotoPlace(photo int64, user int64, place Place) (bool, DbError) {
+	var dbErr DbError
+	var affected int64
+
+	query := fmt.Sprintf("UPDATE %s SET place_name=?, latitude=?, longitude=?, geohash=? WHERE id=? AND owner=?", PhotoTable)
+	res, err := db.c.Exec(query, place.Name, place.Latitude, place.Longitude,
+		geo.Encode(place.Latitude, place.Longitude, geo.MaxPrecision), photo, user)
+	if err != nil {
+		dbErr.InternalError = err
+		return false, dbErr
+	} else {
+		affected, _ = res.RowsAffected()
+	}
+
+	return affected > 0, dbErr
+}
+
+// Photo has to belong to the authenticated user
+func (db *appdbimpl) RemovePhotoPlace(photo int64, user int64) (bool, DbError) {
+	var dbErr DbError
+	var affected int64
+
+	query := fmt.Sprintf("UPDATE %s SET place_name=NULL, latitude=NULL, longitude=NULL, geohash=NULL "+
+		"WHERE id=? AND owner=? AND geohash IS NOT NULL", PhotoTable)
+	res, err := db.c.Exec(query, photo, user)
+	if err != nil {
+		dbErr.InternalError = err
+		return false, dbErr
+	} else {
+		affected, _ = res.RowsAffected()
+	}
+
+	return affected > 0, dbErr
+}
+
+// placesInBoxCondition returns the condition, and its arguments, selecting the photos with a place inside the box.
+// The geohash ranges let SQLite use the photo_geohash index, the coordinates discard the points of the cells outside
+// the box.
+func placesInBoxCondition(box geo.Box, precision int) (string, []interface{}) {
+	var ranges []string
+	var args []interface{}
+
+	for _, prefix := range geo.Cover(box, precision) {
+		ranges = append(ranges, "(Photo.geohash >= ? AND Photo.geohash < ?)")
+		args = append(args, prefix, geo.PrefixUpperBound(prefix))
+	}
+
+	condition := fmt.Sprintf("(%s) AND Photo.latitude BETWEEN ? AND ? AND Photo.longitude BETWEEN ? AND ?",
+		strings.Join(ranges, " OR "))
+	args = append(args, box.MinLat, box.MaxLat, box.MinLon, box.MaxLon)
+
+	return condition, args
+}
+
+// GetNearbyPhotos returns at most amount photos within radius meters from the given point, nearest first. Only the
+// photos the authenticated user can see are included.
+func (db *appdbimpl) GetNearbyPhotos(authUserId int64, lat float64, lon float64, radius float64, amount int64) ([]Photo, DbError) {
+	var dbErr DbError
+
+	box := geo.BoxAround(lat, lon, radius)
+	condition, args := placesInBoxCondition(box, geo.BoxPrecision(box, maxCoverCells))
+
+	// Photos are ordered by squared distance on the plane, longitudes scaled to the latitude of the point, which is close
+	// enough to the Haversine order to pick the candidates at the SQL level
+	lonScale := math.Cos(lat * math.Pi / 180)
+	query := fmt.Sprintf("SELECT %s FROM %s, %s WHERE Photo.owner=User.id AND %s AND %s AND %s ORDER BY "+
+		"(Photo.latitude-?)*(Photo.latitude-?) + ?*(Photo.longitude-?)*(Photo.longitude-?), Photo.id LIMIT ?",
+		photoColumns, PhotoTable, UserTable, listedPhoto, condition, photoVisibleTo("?"))
+	args = append(args, viewerArgs(authUserId)...)
+	args = append(args, lat, lat, lonScale*lonScale, lon, lon, amount*nearbyCandidatesFactor)
+	rows, err := db.c.Query(query, args...)
+	if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
+	}
+
+	defer rows.Close()
+
+	photos, dbErr := db.scanPhotos(rows, authUserId)
+	if dbErr.InternalError != nil {
+		return nil, dbErr
+	}
+
+	// The box is larger than the circle, keep only the photos inside it
+	var nearbyPhotos []Photo
+	for _, photo := range photos {
+		if geo.Distance(lat, lon, photo.Place.Latitude, photo.Place.Longitude) <= radius {
+			nearbyPhotos = append(nearbyPhotos, photo)
+		}
+	}
+
+	sort.SliceStable(nearbyPhotos, func(i, j int) bool {
+		return geo.Distance(lat, lon, nearbyPhotos[i].Place.Latitude, nearbyPhotos[i].Place.Longitude) <
+			geo.Distance(lat, lon, nearbyPhotos[j].Place.Latitude, nearbyPhotos[j].Place.Longitude)
+	})
+
+	if int64(len(nearbyPhotos)) > amount {
+		nearbyPhotos = nearbyPhotos[:amount]
+	}
+
+	return nearbyPhotos, dbErr
+}
+
+// GetPlaceClusters groups the photos inside the box by geohash cell, so that a map shows one marker per cell. Cells
+// are one level finer than the ones used to cover the box. Only the photos the authenticated user can see are counted.
+func (db *appdbimpl) GetPlaceClusters(authUserId int64, box geo.Box) ([]PlaceCluster, DbError) {
+	var dbErr DbError
+	var clusters []PlaceCluster
+
+	precision := geo.BoxPrecision(box, maxCoverCells)
+	clusterPrecision := precision + 1
+	if clusterPrecision > geo.MaxPrecision {
+		clusterPrecision = geo.MaxPrecision
+	}
+	condition, args := placesInBoxCondition(box, precision)
+
+	// The newest photo of every cell is used as cover of the marker: with a single max() aggregate, SQLite takes the
+	// bare columns (the owner) from the row holding the maximum
+	query := fmt.Sprintf("SELECT substr(Photo.geohash, 1, ?) AS cell, count(*), avg(Photo.latitude), avg(Photo.longitude),"+
+		" max(Photo.id), Photo.owner, User.name FROM %s, %s WHERE Photo.owner=User.id AND %s AND %s AND %s"+
+		" GROUP BY cell ORDER BY count(*) DESC", PhotoTable, UserTable, listedPhoto, condition, photoVisibleTo("?"))
+	args = append([]interface{}{clusterPrecision}, args...)
+	rows, err := db.c.Query(query, append(args, viewerArgs(authUserId)...)...)
+	if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var cluster PlaceCluster
+		err = rows.Scan(&cluster.Geohash, &cluster.Count, &cluster.Latitude, &cluster.Longitude, &cluster.CoverId,
+			&cluster.CoverOwner.Id, &cluster.CoverOwner.Username)
+		if err != nil {
+			dbErr.InternalError = err
+			return nil, dbErr
+		}
+		clusters = append(clusters, cluster)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
+	}
+
+	return clusters, dbErr
+}
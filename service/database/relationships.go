@@ -0,0 +1,57 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GetRelationships returns the relationships between the viewer and every given user, sorted by user id. Ids of
+// missing users are ignored.
+func (db *appdbimpl) GetRelationships(viewer int64, users []int64) ([]Relationship, DbError) {
+	var dbErr DbError
+	var relationships []Relationship
+
+	if len(users) == 0 {
+		return relationships, dbErr
+	}
+
+	args := []interface{}{viewer, viewer, viewer, viewer, viewer}
+	for _, user := range users {
+		args = append(args, user)
+	}
+
+	query := fmt.Sprintf("SELECT User.id, "+
+		"EXISTS(SELECT * FROM %s WHERE follower=? AND following=User.id), "+
+		"EXISTS(SELECT * FROM %s WHERE follower=User.id AND following=?), "+
+		"EXISTS(SELECT * FROM %s WHERE banning=? AND banned=User.id), "+
+		"EXISTS(SELECT * FROM %s WHERE muting=? AND muted=User.id), "+
+		"EXISTS(SELECT * FROM %s WHERE requester=? AND requested=User.id) "+
+		"FROM %s WHERE User.id IN (%s) ORDER BY User.id", FollowTable, FollowTable, BanTable, MuteTable, FollowRequestTable,
+		UserTable, strings.TrimSuffix(strings.Repeat("?, ", len(users)), ", "))
+	rows, err := db.c.Query(query, args...)
+	if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var relationship Relationship
+		err = rows.Scan(&relationship.UserId, &relationship.Following, &relationship.FollowedBy, &relationship.Banned,
+			&relationship.Muted, &relationship.Requested)
+		if err != nil {
+			dbErr.InternalError = err
+			return nil, dbErr
+		}
+		relationships = append(relationships, relationship)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
+	}
+
+	return relationships, dbErr
+}
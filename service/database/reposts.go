@@ -0,0 +1,67 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mattn/go-sqlite3"
+)
+
+// repostColumns are the attribution columns of a reposted photo read by scanPhotos after photoColumns: the reposter,
+// left joined as Reposter, and the time of their repost, left joined as Reposted
+const repostColumns = "Reposter.id, Reposter.name, Reposted.created_at"
+
+// RepostPhoto shares the photo with the followers of the user, who has to be able to see it
+func (db *appdbimpl) RepostPhoto(authUser int64, photo int64) (bool, DbError) {
+	var dbErr DbError
+	var affected int64
+
+	query := fmt.Sprintf("INSERT INTO %s (owner, photo) VALUES (?, ?)", RepostTable)
+	res, err := db.c.Exec(query, authUser, photo)
+
+	if err != nil {
+		var sqlErr sqlite3.Error
+		if errors.As(err, &sqlErr) {
+			if errors.Is(sqlErr.ExtendedCode, sqlite3.ErrConstraintPrimaryKey) {
+				dbErr.Code = StateConflict
+			}
+		}
+		dbErr.InternalError = err
+		return false, dbErr
+	} else {
+		affected, _ = res.RowsAffected()
+	}
+
+	return affected > 0, dbErr
+}
+
+// UnrepostPhoto undoes the repost of the photo by the user
+func (db *appdbimpl) UnrepostPhoto(authUser int64, photo int64) (bool, DbError) {
+	var dbErr DbError
+	var affected int64
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE owner=? AND photo=?", RepostTable)
+	res, err := db.c.Exec(query, authUser, photo)
+
+	if err != nil {
+		dbErr.InternalError = err
+		return false, dbErr
+	} else {
+		affected, _ = res.RowsAffected()
+	}
+
+	return affected > 0, dbErr
+}
+
+// isPhotoRepostedBy returns true if the user reposted the photo
+func (db *appdbimpl) isPhotoRepostedBy(photo int64, user int64) (bool, DbError) {
+	var dbErr DbError
+	var count int
+
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE photo=? AND owner=?", RepostTable)
+	err := db.c.QueryRow(query, photo, user).Scan(&count)
+	if err != nil {
+		dbErr.InternalError = err
+	}
+
+	return count > 0, dbErr
+}
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"fmt"
+	"time"
+	"wasaphoto/service/globaltime"
+)
+
+// GetScheduledPhotos returns the photos of the user waiting to be published, the next to be published first
+func (db *appdbimpl) GetScheduledPhotos(owner int64, amount int64, offset int64) ([]Photo, DbError) {
+	var dbErr DbError
+
+	query := fmt.Sprintf("SELECT %s FROM %s, %s WHERE Photo.owner=User.id AND Photo.owner=? AND Photo.publish_at IS NOT NULL "+
+		"ORDER BY Photo.publish_at, Photo.id LIMIT ? OFFSET ?", photoColumns, PhotoTable, UserTable)
+	rows, err := db.c.Query(query, owner, amount, offset)
+	if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
+	}
+
+	defer rows.Close()
+
+	return db.scanPhotos(rows, owner)
+}
+
+// ReschedulePhoto changes when the photo will be published. Photo has to belong to the authenticated user and has to be
+// still scheduled.
+func (db *appdbimpl) ReschedulePhoto(photo int64, owner int64, publishAt time.Time) (bool, DbError) {
+	var dbErr DbError
+	var affected int64
+
+	query := fmt.Sprintf("UPDATE %s SET publish_at=? WHERE id=? AND owner=? AND publish_at IS NOT NULL", PhotoTable)
+	res, err := db.c.Exec(query, formatTimestamp(publishAt), photo, owner)
+	if err != nil {
+		dbErr.InternalError = err
+		return false, dbErr
+	} else {
+		affected, _ = res.RowsAffected()
+	}
+
+	return affected > 0, dbErr
+}
+
+// CancelScheduledPhoto deletes a photo before it is published. Photo has to belong to the authenticated user and has to
+// be still scheduled.
+func (db *appdbimpl) CancelScheduledPhoto(photo int64, owner int64) (bool, DbError) {
+	var dbErr DbError
+	var affected int64
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE id=? AND owner=? AND publish_at IS NOT NULL", PhotoTable)
+	res, err := db.c.Exec(query, photo, owner)
+	if err != nil {
+		dbErr.InternalError = err
+		return false, dbErr
+	} else {
+		affected, _ = res.RowsAffected()
+	}
+
+	return affected > 0, dbErr
+}
+
+// PublishScheduledPhotos publishes the photos whose publish time has come, using it as upload time, and returns how
+// many have been published
+func (db *appdbimpl) PublishScheduledPhotos() (int64, DbError) {
+	var dbErr DbError
+	var affected int64
+
+	query := fmt.Sprintf("UPDATE %s SET uploaded_at=publish_at, publish_at=NULL WHERE publish_at <= ?", PhotoTable)
+	res, err := db.c.Exec(query, formatTimestamp(globaltime.Now()))
+	if err != nil {
+		dbErr.InternalError = err
+		return 0, dbErr
+	}
+
+	affected, _ = res.RowsAffected()
+
+	return affected, dbErr
+}
@@ -0,0 +1,208 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"wasaphoto/service/globaltime"
+)
+
+// StoryDuration is how long a story is visible after being posted
+const StoryDuration = 24 * time.Hour
+
+// timestampLayout is the format used by SQLite current_timestamp. Timestamps computed in Go are stored with the same
+// format so that they can be compared with the ones computed by SQLite.
+const timestampLayout = "2006-01-02 15:04:05"
+
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Format(timestampLayout)
+}
+
+// DoesStoryBelongToUser tells whether the story, expired or not, was posted by the user
+func (db *appdbimpl) DoesStoryBelongToUser(userId int64, story int64) bool {
+	var count int
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE id=? AND owner=?", StoryTable)
+	err := db.c.QueryRow(query, story, userId).Scan(&count)
+
+	if err != nil {
+		return false
+	}
+
+	return count > 0
+}
+
+// InsertStory posts a story expiring StoryDuration from now and returns its id and timestamps, as they are stored
+func (db *appdbimpl) InsertStory(image []byte, owner int64) (Story, DbError) {
+	var dbErr DbError
+	var story Story
+
+	now := globaltime.Now()
+	query := fmt.Sprintf("INSERT INTO %s (owner, image, created_at, expires_at) VALUES (?, ?, ?, ?)", StoryTable)
+	res, err := db.c.Exec(query, owner, image, formatTimestamp(now), formatTimestamp(now.Add(StoryDuration)))
+	if err != nil {
+		dbErr.InternalError = err
+		return story, dbErr
+	}
+
+	story.Id, err = res.LastInsertId()
+	if err != nil {
+		dbErr.InternalError = err
+		return story, dbErr
+	}
+
+	// Timestamps are read back so that they have the same format as in the tray and in the viewers
+	query = fmt.Sprintf("SELECT created_at, expires_at FROM %s WHERE id=?", StoryTable)
+	err = db.c.QueryRow(query, story.Id).Scan(&story.CreatedAt, &story.ExpiresAt)
+	if err != nil {
+		dbErr.InternalError = err
+	}
+
+	return story, dbErr
+}
+
+// GetStoryImage returns the image of the story if it belongs to the user in path and it is not expired
+func (db *appdbimpl) GetStoryImage(story int64, owner int64) ([]byte, DbError) {
+	var dbErr DbError
+	var image []byte
+
+	query := fmt.Sprintf("SELECT image FROM %s WHERE id=? AND owner=? AND expires_at > ?", StoryTable)
+	err := db.c.QueryRow(query, story, owner, formatTimestamp(globaltime.Now())).Scan(&image)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			dbErr.Code = StateConflict
+		}
+		dbErr.InternalError = err
+	}
+
+	return image, dbErr
+}
+
+// MarkStorySeen records the first time the viewer has seen the story
+func (db *appdbimpl) MarkStorySeen(story int64, viewer int64) DbError {
+	var dbErr DbError
+
+	query := fmt.Sprintf("INSERT OR IGNORE INTO %s (story, viewer, seen_at) VALUES (?, ?, ?)", StoryViewTable)
+	_, err := db.c.Exec(query, story, viewer, formatTimestamp(globaltime.Now()))
+	if err != nil {
+		dbErr.InternalError = err
+	}
+
+	return dbErr
+}
+
+// Story has to belong to the authenticated user
+func (db *appdbimpl) DeleteStory(story int64, owner int64) (bool, DbError) {
+	var dbErr DbError
+	var affected int64
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE id=? AND owner=?", StoryTable)
+	res, err := db.c.Exec(query, story, owner)
+	if err != nil {
+		dbErr.InternalError = err
+		return false, dbErr
+	} else {
+		affected, _ = res.RowsAffected()
+	}
+
+	return affected > 0, dbErr
+}
+
+// GetStoriesTray returns the active stories of the viewer and of the users they follow, grouped by author. Authors
+// with the most recent story come first, the stories of every author are in chronological order.
+func (db *appdbimpl) GetStoriesTray(viewer int64) ([]StoryAuthor, DbError) {
+	var dbErr DbError
+	var authors []StoryAuthor
+
+	now := formatTimestamp(globaltime.Now())
+	query := fmt.Sprintf("SELECT Story.id, Story.owner, User.name, Story.created_at, Story.expires_at, "+
+		"EXISTS(SELECT * FROM %s WHERE story=Story.id AND viewer=?) FROM %s, %s WHERE Story.owner=User.id AND "+
+		"Story.expires_at > ? AND (Story.owner=? OR Story.owner IN (SELECT following FROM %s WHERE follower=?)) AND "+
+		"NOT EXISTS(SELECT * FROM %s WHERE banning=Story.owner AND banned=?) ORDER BY (SELECT max(s.created_at) FROM %s s "+
+		"WHERE s.owner=Story.owner AND s.expires_at > ?) DESC, Story.owner, Story.created_at, Story.id",
+		StoryViewTable, StoryTable, UserTable, FollowTable, BanTable, StoryTable)
+	rows, err := db.c.Query(query, viewer, now, viewer, viewer, viewer, now)
+	if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var story Story
+		err = rows.Scan(&story.Id, &story.Owner.Id, &story.Owner.Username, &story.CreatedAt, &story.ExpiresAt, &story.Seen)
+		if err != nil {
+			dbErr.InternalError = err
+			return nil, dbErr
+		}
+
+		// Rows are sorted by author, a new author starts a new group
+		if len(authors) == 0 || authors[len(authors)-1].User.Id != story.Owner.Id {
+			authors = append(authors, StoryAuthor{User: story.Owner, AllSeen: true})
+		}
+		author := &authors[len(authors)-1]
+		author.Stories = append(author.Stories, story)
+		author.AllSeen = author.AllSeen && story.Seen
+	}
+
+	err = rows.Err()
+	if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
+	}
+
+	return authors, dbErr
+}
+
+// GetStoryViewers returns who has seen the story, latest first. Story has to belong to the authenticated user.
+func (db *appdbimpl) GetStoryViewers(story int64, owner int64) ([]StoryViewer, DbError) {
+	var dbErr DbError
+	var viewers []StoryViewer
+
+	query := fmt.Sprintf("SELECT User.id, User.name, %s.seen_at FROM %s, %s, %s WHERE %s.viewer=User.id AND "+
+		"%s.story=Story.id AND Story.id=? AND Story.owner=? ORDER BY %s.seen_at DESC", StoryViewTable, StoryViewTable,
+		StoryTable, UserTable, StoryViewTable, StoryViewTable, StoryViewTable)
+	rows, err := db.c.Query(query, story, owner)
+	if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var viewer StoryViewer
+		err = rows.Scan(&viewer.User.Id, &viewer.User.Username, &viewer.SeenAt)
+		if err != nil {
+			dbErr.InternalError = err
+			return nil, dbErr
+		}
+		viewers = append(viewers, viewer)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
+	}
+
+	return viewers, dbErr
+}
+
+// PurgeExpiredStories deletes the expired stories, with their views, and returns how many have been deleted
+func (db *appdbimpl) PurgeExpiredStories() (int64, DbError) {
+	var dbErr DbError
+	var affected int64
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE expires_at <= ?", StoryTable)
+	res, err := db.c.Exec(query, formatTimestamp(globaltime.Now()))
+	if err != nil {
+		dbErr.InternalError = err
+		return 0, dbErr
+	}
+
+	affected, _ = res.RowsAffected()
+
+	return affected, dbErr
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+	"wasaphoto/service/globaltime"
+)
+
+const (
+	// SuggestionActivityWindow is how far back the photos of a candidate count as recent activity
+	SuggestionActivityWindow = 7 * 24 * time.Hour
+
+	// Weights of the signals ranking the suggestions
+	mutualFollowWeight = 3
+	interactionWeight  = 2
+	recentPhotoWeight  = 1
+)
+
+// GetFollowSuggestions ranks the accounts the user may want to follow by how many of the followed users follow them,
+// how much they interacted with the user photos (and the other way round) and how many photos they recently posted
+// that the user can see. Who is followed, has a pending request, or is banned in either direction is excluded.
+func (db *appdbimpl) GetFollowSuggestions(userId int64, amount int64) ([]Suggestion, DbError) {
+	var dbErr DbError
+	var suggestions []Suggestion
+
+	// Candidates come only from the sets the signals are computed on, each grouped by candidate: the accounts followed
+	// by the followed users, the ones the user interacted with, and the ones who recently posted. The follower shown in
+	// the reasons is the one followed most recently by the user, taken from the row of max(Mine.rowid).
+	mutuals := fmt.Sprintf("SELECT Theirs.following AS user, count(*) AS mutual, Mine.following AS friend, "+
+		"0 AS interactions, 0 AS recent, max(Mine.rowid) FROM %[1]s AS Mine, %[1]s AS Theirs WHERE Mine.follower=? AND "+
+		"Theirs.follower=Mine.following GROUP BY Theirs.following", FollowTable)
+	interactions := fmt.Sprintf("SELECT user, 0, NULL, count(*), 0, NULL FROM ("+
+		"SELECT Liked.owner AS user FROM %[1]s AS Liked, %[3]s WHERE Liked.photo=Photo.id AND Photo.owner=? UNION ALL "+
+		"SELECT Photo.owner FROM %[1]s AS Liked, %[3]s WHERE Liked.photo=Photo.id AND Liked.owner=? UNION ALL "+
+		"SELECT %[2]s.owner FROM %[2]s, %[3]s WHERE %[2]s.photo=Photo.id AND Photo.owner=? UNION ALL "+
+		"SELECT Photo.owner FROM %[2]s, %[3]s WHERE %[2]s.photo=Photo.id AND %[2]s.owner=?) GROUP BY user",
+		LikeTable, CommentTable, PhotoTable)
+	recents := fmt.Sprintf("SELECT Photo.owner, 0, NULL, 0, count(*), NULL FROM %s WHERE Photo.uploaded_at >= ? AND "+
+		"%s AND %s GROUP BY Photo.owner", PhotoTable, listedPhoto, photoVisibleTo("?"))
+	candidates := fmt.Sprintf("SELECT user, sum(mutual) AS mutual, max(friend) AS friend, sum(interactions) AS "+
+		"interactions, sum(recent) AS recent FROM (%s UNION ALL %s UNION ALL %s) GROUP BY user", mutuals, interactions,
+		recents)
+
+	query := fmt.Sprintf("SELECT User.id, User.name, mutual, Friend.name, interactions, recent FROM (%s) AS Candidate "+
+		"JOIN %[2]s ON User.id=Candidate.user LEFT JOIN %[2]s AS Friend ON Friend.id=Candidate.friend WHERE User.id<>? "+
+		"AND NOT EXISTS(SELECT * FROM %[3]s WHERE follower=? AND following=User.id) "+
+		"AND NOT EXISTS(SELECT * FROM %[4]s WHERE requester=? AND requested=User.id) "+
+		"AND NOT EXISTS(SELECT * FROM %[5]s WHERE (banning=? AND banned=User.id) OR (banning=User.id AND banned=?)) "+
+		"ORDER BY %[6]d * mutual + %[7]d * interactions + %[8]d * recent DESC, User.id LIMIT ?", candidates, UserTable,
+		FollowTable, FollowRequestTable, BanTable, mutualFollowWeight, interactionWeight, recentPhotoWeight)
+
+	since := formatTimestamp(globaltime.Now().Add(-SuggestionActivityWindow))
+	args := []interface{}{userId, userId, userId, userId, userId, since}
+	args = append(args, viewerArgs(userId)...)
+	args = append(args, userId, userId, userId, userId, userId, amount)
+	rows, err := db.c.Query(query, args...)
+	if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var suggestion Suggestion
+		var friend sql.NullString
+		err = rows.Scan(&suggestion.User.Id, &suggestion.User.Username, &suggestion.MutualFollows, &friend,
+			&suggestion.Interactions, &suggestion.RecentPhotos)
+		if err != nil {
+			dbErr.InternalError = err
+			return nil, dbErr
+		}
+
+		suggestion.MutualFollow = friend.String
+		suggestions = append(suggestions, suggestion)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		dbErr.InternalError = err
+		return nil, dbErr
+	}
+
+	return suggestions, dbErr
+}
@@ -4,41 +4,34 @@ import (
 	"fmt"
 )
 
+// GetMyStream returns the photos of the users followed by the user, and the photos they reposted, newest first. A
+// reposted photo is shown once, attributed to its latest reposter, and only if its owner isn't followed already.
 func (db *appdbimpl) GetMyStream(userId int64, offset int64, amount int64) ([]Photo, DbError) {
 	var dbErr DbError
 
-	query := fmt.Sprintf("SELECT Photo.id, User.name, owner, uploaded_at FROM %s, %s WHERE owner=User.id AND"+
-		" owner IN (SELECT following FROM %s WHERE follower=?) ORDER BY uploaded_at DESC LIMIT ? OFFSET ?", PhotoTable, UserTable, FollowTable)
-	rows, err := db.c.Query(query, userId, amount, offset)
-
-	var photos []Photo
+	followed := fmt.Sprintf("IN (SELECT following FROM %s WHERE follower=?)", FollowTable)
+	muted := fmt.Sprintf("IN (SELECT muted FROM %s WHERE muting=?)", MuteTable)
+
+	// Photos and reposts of muted users are hidden, even if they are still followed, and so are reposts of their photos
+	stream := fmt.Sprintf("SELECT id AS photo, NULL AS reposter, uploaded_at AS sorted_at FROM %[1]s WHERE owner %[3]s "+
+		"AND owner NOT %[4]s UNION ALL SELECT photo, owner, max(created_at) FROM %[2]s WHERE owner %[3]s AND "+
+		"owner NOT %[4]s GROUP BY photo", PhotoTable, RepostTable, followed, muted)
+	query := fmt.Sprintf("SELECT %s, %s FROM (%s) AS Stream JOIN %s ON Photo.id=Stream.photo JOIN %s ON "+
+		"Photo.owner=User.id LEFT JOIN %s AS Reposter ON Reposter.id=Stream.reposter LEFT JOIN %s AS Reposted ON "+
+		"Reposted.photo=Stream.photo AND Reposted.owner=Stream.reposter WHERE %s AND %s AND "+
+		"(Stream.reposter IS NULL OR (Photo.owner<>? AND Photo.owner NOT %s AND Photo.owner NOT %s)) "+
+		"ORDER BY Stream.sorted_at DESC LIMIT ? OFFSET ?", photoColumns, repostColumns, stream, PhotoTable, UserTable,
+		UserTable, RepostTable, listedPhoto, photoVisibleTo("?"), followed, muted)
+	args := append([]interface{}{userId, userId, userId, userId}, viewerArgs(userId)...)
+	args = append(args, userId, userId, userId)
+	rows, err := db.c.Query(query, append(args, amount, offset)...)
 
 	if err != nil {
 		dbErr.InternalError = err
-	} else {
-		for rows.Next() {
-			var photo Photo
-			err = rows.Scan(&photo.Id, &photo.Owner.Username, &photo.Owner.Id, &photo.UploadedAt)
-			if err != nil {
-				dbErr.InternalError = err
-				return nil, dbErr
-			}
-
-			photo.PhotoInfo, dbErr = db.getPhotoCounters(photo.Id)
-			if dbErr.InternalError != nil {
-				return nil, dbErr
-			}
-
-			photos = append(photos, photo)
-		}
-
-		if rows.Err() != nil {
-			dbErr.InternalError = err
-			return nil, dbErr
-		}
+		return nil, dbErr
 	}
 
 	defer rows.Close()
 
-	return photos, dbErr
+	return db.scanPhotos(rows, userId)
 }
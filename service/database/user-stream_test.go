@@ -0,0 +1,74 @@
+package database
+
+import (
+	"testing"
+)
+
+func TestGetMyStreamWithReposts(t *testing.T) {
+	db, _ := newTestDatabase(t)
+	ids := createTestUsers(t, db, "viewer", "alice", "bob", "carol")
+	viewer, alice, bob, carol := ids[0], ids[1], ids[2], ids[3]
+	follow(t, db, viewer, alice)
+	follow(t, db, viewer, bob)
+
+	carolPhoto := insertTestPhoto(t, db, carol, PublicVisibility)
+	restrictedPhoto := insertTestPhoto(t, db, carol, FollowersVisibility)
+	alicePhoto := insertTestPhoto(t, db, alice, PublicVisibility)
+	viewerPhoto := insertTestPhoto(t, db, viewer, PublicVisibility)
+
+	repost := func(user int64, photo int64, at string) {
+		t.Helper()
+		if _, dbErr := db.RepostPhoto(user, photo); dbErr.InternalError != nil {
+			t.Fatalf("reposting photo: %v", dbErr.InternalError)
+		}
+		if _, err := db.c.Exec("UPDATE Repost SET created_at=? WHERE owner=? AND photo=?", at, user, photo); err != nil {
+			t.Fatalf("setting repost time: %v", err)
+		}
+	}
+
+	// Upload and repost times are set so that the order doesn't depend on the clock
+	_, err := db.c.Exec("UPDATE Photo SET uploaded_at=CASE id WHEN ? THEN '2023-01-01 10:00:00' WHEN ? THEN "+
+		"'2023-01-01 10:00:00' ELSE '2023-01-02 10:00:00' END", carolPhoto, restrictedPhoto)
+	if err != nil {
+		t.Fatalf("setting upload times: %v", err)
+	}
+	repost(alice, carolPhoto, "2023-01-03 10:00:00")
+	repost(bob, carolPhoto, "2023-01-04 10:00:00")
+	// Reposts of photos of followed users, of the viewer, or that the viewer can't see are not shown
+	repost(bob, alicePhoto, "2023-01-05 10:00:00")
+	repost(alice, viewerPhoto, "2023-01-05 10:00:00")
+	repost(alice, restrictedPhoto, "2023-01-05 10:00:00")
+
+	stream, dbErr := db.GetMyStream(viewer, 0, 10)
+	if dbErr.InternalError != nil {
+		t.Fatalf("getting stream: %v", dbErr.InternalError)
+	}
+	if len(stream) != 2 || stream[0].Id != carolPhoto || stream[1].Id != alicePhoto {
+		t.Fatalf("got %d photos, want the repost of carol's photo and then alice's photo", len(stream))
+	}
+
+	// The reposted photo is shown once, attributed to its latest reposter
+	if stream[0].RepostedBy == nil || stream[0].RepostedBy.Id != bob {
+		t.Errorf("reposted by %v, want bob", stream[0].RepostedBy)
+	}
+	if stream[1].RepostedBy != nil {
+		t.Errorf("photo of a followed user attributed to %v", stream[1].RepostedBy)
+	}
+
+	// The repost time has the same format as the upload time
+	if stream[0].RepostedAt != "2023-01-04T10:00:00Z" || stream[1].UploadedAt != "2023-01-02T10:00:00Z" {
+		t.Errorf("got repost time %q and upload time %q", stream[0].RepostedAt, stream[1].UploadedAt)
+	}
+
+	// Reposts of muted users are hidden, the photo goes back to the previous reposter
+	if _, dbErr = db.TargetUser(viewer, bob, MuteTable); dbErr.InternalError != nil {
+		t.Fatalf("muting user: %v", dbErr.InternalError)
+	}
+	stream, dbErr = db.GetMyStream(viewer, 0, 10)
+	if dbErr.InternalError != nil {
+		t.Fatalf("getting stream: %v", dbErr.InternalError)
+	}
+	if len(stream) != 2 || stream[0].Id != carolPhoto || stream[0].RepostedBy == nil || stream[0].RepostedBy.Id != alice {
+		t.Errorf("got %d photos, want carol's photo attributed to alice", len(stream))
+	}
+}
@@ -1,26 +1,47 @@
 package database
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"github.com/mattn/go-sqlite3"
+	"wasaphoto/service/globaltime"
 )
 
+// execer runs statements, on the database connection or inside a transaction
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
 func (db *appdbimpl) TargetUser(authUserId int64, userId int64, tableName string) (bool, DbError) {
+	if tableName == BanTable {
+		return db.BanUser(authUserId, userId, false)
+	}
+
+	return targetUser(db.c, authUserId, userId, tableName)
+}
+
+// targetUser adds the relation of tableName from authUserId to userId. Bans run several statements, so ex has to be a
+// transaction for them to be atomic.
+func targetUser(ex execer, authUserId int64, userId int64, tableName string) (bool, DbError) {
 	var dbErr DbError
 	var query string
 
 	switch tableName {
 	case BanTable:
-		query = fmt.Sprintf("INSERT INTO %s (banning, banned) VALUES (?, ?)", BanTable)
+		return banUser(ex, authUserId, userId, false)
 	case FollowTable:
 		query = fmt.Sprintf("INSERT INTO %s (follower, following) VALUES (?, ?)", FollowTable)
+	case MuteTable:
+		query = fmt.Sprintf("INSERT INTO %s (muting, muted) VALUES (?, ?)", MuteTable)
+	case CloseFriendTable:
+		query = fmt.Sprintf("INSERT INTO %s (owner, friend) VALUES (?, ?)", CloseFriendTable)
 	default:
 		return false, dbErr
 	}
 
 	var affected int64
-	res, err := db.c.Exec(query, authUserId, userId)
+	res, err := ex.Exec(query, authUserId, userId)
 	if err != nil {
 		var sqlErr sqlite3.Error
 		if errors.As(err, &sqlErr) {
@@ -38,6 +59,108 @@ func (db *appdbimpl) TargetUser(authUserId int64, userId int64, tableName string
 	return affected > 0, dbErr
 }
 
+// BanUser bans the user and removes the follows and the follow requests between the two users, in both directions. If
+// purge is true, the likes and the comments of the banned user on the photos of the banning one are deleted too.
+// Everything is done atomically: a conflict is returned, and nothing is changed, if the user is already banned.
+func (db *appdbimpl) BanUser(banning int64, banned int64, purge bool) (bool, DbError) {
+	tx, err := db.c.Begin()
+	if err != nil {
+		return false, DbError{InternalError: err}
+	}
+
+	isBanned, dbErr := banUser(tx, banning, banned, purge)
+	if dbErr.InternalError == nil {
+		dbErr.InternalError = tx.Commit()
+	} else {
+		_ = tx.Rollback()
+	}
+
+	if dbErr.InternalError != nil {
+		return false, dbErr
+	}
+
+	return isBanned, dbErr
+}
+
+// banUser runs the statements of BanUser on ex, stopping at the first failing one
+func banUser(ex execer, banning int64, banned int64, purge bool) (bool, DbError) {
+	var dbErr DbError
+	var affected int64
+
+	query := fmt.Sprintf("INSERT INTO %s (banning, banned) VALUES (?, ?)", BanTable)
+	res, err := ex.Exec(query, banning, banned)
+	if err != nil {
+		var sqlErr sqlite3.Error
+		if errors.As(err, &sqlErr) && errors.Is(sqlErr.ExtendedCode, sqlite3.ErrConstraintPrimaryKey) {
+			dbErr.Code = StateConflict
+		}
+	} else {
+		affected, _ = res.RowsAffected()
+	}
+
+	if err == nil {
+		query = fmt.Sprintf("DELETE FROM %s WHERE (follower=? AND following=?) OR (follower=? AND following=?)", FollowTable)
+		_, err = ex.Exec(query, banned, banning, banning, banned)
+	}
+
+	if err == nil {
+		query = fmt.Sprintf("DELETE FROM %s WHERE (requester=? AND requested=?) OR (requester=? AND requested=?)",
+			FollowRequestTable)
+		_, err = ex.Exec(query, banned, banning, banning, banned)
+	}
+
+	// Reposts need both users to see each other, so they go in both directions
+	if err == nil {
+		query = fmt.Sprintf("DELETE FROM %s WHERE (owner=? AND photo IN (SELECT id FROM %s WHERE owner=?)) OR "+
+			"(owner=? AND photo IN (SELECT id FROM %s WHERE owner=?))", RepostTable, PhotoTable, PhotoTable)
+		_, err = ex.Exec(query, banned, banning, banning, banned)
+	}
+
+	if err == nil && purge {
+		query = fmt.Sprintf("DELETE FROM %s WHERE owner=? AND photo IN (SELECT id FROM %s WHERE owner=?)", LikeTable,
+			PhotoTable)
+		_, err = ex.Exec(query, banned, banning)
+		if err == nil {
+			query = fmt.Sprintf("DELETE FROM %s WHERE owner=? AND comment IN (SELECT %s.id FROM %s, %s WHERE "+
+				"%s.photo=%s.id AND %s.owner=?)", CommentLikeTable, CommentTable, CommentTable, PhotoTable, CommentTable,
+				PhotoTable, PhotoTable)
+			_, err = ex.Exec(query, banned, banning)
+		}
+		// Comments with replies stay as placeholders, like when their owner deletes them
+		if err == nil {
+			query = fmt.Sprintf("DELETE FROM %s WHERE comment IN (SELECT %s.id FROM %s, %s WHERE %s.photo=%s.id AND "+
+				"%s.owner=? AND %s.owner=?)", CommentRevisionTable, CommentTable, CommentTable, PhotoTable, CommentTable,
+				PhotoTable, CommentTable, PhotoTable)
+			_, err = ex.Exec(query, banned, banning)
+		}
+		// Comments without replies are deleted first, then the placeholders left without replies, going up the threads
+		// like DeleteComment does, until nothing else can be deleted. The comments still replied to become placeholders.
+		if err == nil {
+			query = fmt.Sprintf("DELETE FROM %[1]s WHERE photo IN (SELECT id FROM %[2]s WHERE owner=?) AND "+
+				"(owner=? OR deleted_at IS NOT NULL) AND NOT EXISTS(SELECT * FROM %[1]s AS Reply WHERE "+
+				"Reply.parent=%[1]s.id)", CommentTable, PhotoTable)
+			for deleted := int64(1); err == nil && deleted > 0; {
+				res, err = ex.Exec(query, banning, banned)
+				if err == nil {
+					deleted, _ = res.RowsAffected()
+				}
+			}
+		}
+		if err == nil {
+			query = fmt.Sprintf("UPDATE %s SET content='', deleted_at=?, edited_at=NULL WHERE owner=? AND photo IN "+
+				"(SELECT id FROM %s WHERE owner=?) AND deleted_at IS NULL", CommentTable, PhotoTable)
+			_, err = ex.Exec(query, formatTimestamp(globaltime.Now()), banned, banning)
+		}
+	}
+
+	if err != nil {
+		dbErr.InternalError = err
+		return false, dbErr
+	}
+
+	return affected > 0, dbErr
+}
+
 func (db *appdbimpl) UntargetUser(authUserId int64, userId int64, tableName string) (bool, DbError) {
 
 	var query string
@@ -48,6 +171,10 @@ func (db *appdbimpl) UntargetUser(authUserId int64, userId int64, tableName stri
 		query = fmt.Sprintf("DELETE FROM %s WHERE banning=? AND banned=?", BanTable)
 	case FollowTable:
 		query = fmt.Sprintf("DELETE FROM %s WHERE follower=? AND following=?", FollowTable)
+	case MuteTable:
+		query = fmt.Sprintf("DELETE FROM %s WHERE muting=? AND muted=?", MuteTable)
+	case CloseFriendTable:
+		query = fmt.Sprintf("DELETE FROM %s WHERE owner=? AND friend=?", CloseFriendTable)
 	default:
 	}
 
@@ -62,50 +189,76 @@ func (db *appdbimpl) UntargetUser(authUserId int64, userId int64, tableName stri
 	return affected > 0, dbErr
 }
 
-func (db *appdbimpl) GetUsersList(authUserId int64, tableName string) ([]User, DbError) {
-	var query string
+// Lists of users returned by GetUsersList
+const (
+	FollowingList    string = "following"
+	FollowersList    string = "followers"
+	BannedList       string = "banned"
+	MutedList        string = "muted"
+	CloseFriendsList string = "closeFriends"
+)
+
+// usersListRelations maps every list to the relation table and to the columns of the listed user and of the user
+// owning the list
+var usersListRelations = map[string][3]string{
+	FollowingList:    {FollowTable, "following", "follower"},
+	FollowersList:    {FollowTable, "follower", "following"},
+	BannedList:       {BanTable, "banned", "banning"},
+	MutedList:        {MuteTable, "muted", "muting"},
+	CloseFriendsList: {CloseFriendTable, "friend", "owner"},
+}
+
+// GetUsersList returns a page of the given list of the user, sorted by user id. The page starts after the user id
+// cursor, 0 starts from the beginning; the next cursor is 0 when there are no more pages.
+func (db *appdbimpl) GetUsersList(userId int64, list string, cursor int64, amount int64) (UsersPage, DbError) {
 	var dbErr DbError
-	var users []User
+	var page UsersPage
 
-	switch tableName {
-	case BanTable:
-		query = fmt.Sprintf("SELECT banned FROM %s WHERE banning=?", BanTable)
-	case FollowTable:
-		query = fmt.Sprintf("SELECT following FROM %s WHERE follower=?", FollowTable)
-	default:
-		return []User{}, dbErr
+	relation, ok := usersListRelations[list]
+	if !ok {
+		dbErr.InternalError = fmt.Errorf("unknown users list %q", list)
+		return page, dbErr
 	}
+	table, listedColumn, ownerColumn := relation[0], relation[1], relation[2]
 
-	rows, err := db.c.Query(query, authUserId)
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE %s=?", table, ownerColumn)
+	err := db.c.QueryRow(query, userId).Scan(&page.Total)
+	if err != nil {
+		dbErr.InternalError = err
+		return page, dbErr
+	}
 
+	// One more user than requested tells if there is a next page
+	query = fmt.Sprintf("SELECT User.id, User.name FROM %s, %s WHERE %s.%s=User.id AND %s.%s=? AND User.id > ? "+
+		"ORDER BY User.id LIMIT ?", table, UserTable, table, listedColumn, table, ownerColumn)
+	rows, err := db.c.Query(query, userId, cursor, amount+1)
 	if err != nil {
 		dbErr.InternalError = err
-		return users, dbErr
+		return page, dbErr
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var user User
-		err = rows.Scan(&user.Id)
-		if err != nil {
-			dbErr.InternalError = err
-			return nil, dbErr
-		}
-		query = fmt.Sprintf("SELECT name FROM %s WHERE id=?", UserTable)
-		err = db.c.QueryRow(query, user.Id).Scan(&user.Username)
+		err = rows.Scan(&user.Id, &user.Username)
 		if err != nil {
 			dbErr.InternalError = err
-			return nil, dbErr
+			return page, dbErr
 		}
-		users = append(users, user)
+		page.Users = append(page.Users, user)
 	}
 
 	err = rows.Err()
 	if err != nil {
 		dbErr.InternalError = err
-		return nil, dbErr
+		return page, dbErr
 	}
 
-	defer rows.Close()
+	if int64(len(page.Users)) > amount {
+		page.Users = page.Users[:amount]
+		page.NextCursor = page.Users[amount-1].Id
+	}
 
-	return users, dbErr
+	return page, dbErr
 }
@@ -0,0 +1,102 @@
+package database
+
+import (
+	"testing"
+	"time"
+	"wasaphoto/service/globaltime"
+)
+
+func TestBanUserRemovesRelations(t *testing.T) {
+	db, _ := newTestDatabase(t)
+	ids := createTestUsers(t, db, "banning", "banned")
+	banning, banned := ids[0], ids[1]
+
+	follow(t, db, banning, banned)
+	follow(t, db, banned, banning)
+	photo := insertTestPhoto(t, db, banning, PublicVisibility)
+	if _, dbErr := db.LikePhoto(banned, photo, banning); dbErr.InternalError != nil {
+		t.Fatalf("liking photo: %v", dbErr.InternalError)
+	}
+	comment := commentTestPhoto(t, db, banned, photo, 0)
+
+	ok, dbErr := db.BanUser(banning, banned, false)
+	if dbErr.InternalError != nil || !ok {
+		t.Fatalf("banning user: %t, %v", ok, dbErr.InternalError)
+	}
+
+	for _, relation := range [][2]int64{{banning, banned}, {banned, banning}} {
+		isFollowing, dbErr := db.IsUserTargeted(relation[0], relation[1], FollowTable)
+		if dbErr.InternalError != nil || isFollowing {
+			t.Errorf("user %d still follows %d (%v)", relation[0], relation[1], dbErr.InternalError)
+		}
+	}
+
+	// Without purge likes and comments are kept
+	reaction, _ := db.photoReactionOf(photo, banned)
+	if reaction != HeartReaction {
+		t.Errorf("reaction is %q, want it kept", reaction)
+	}
+	assertCommentStates(t, db, photo, map[int64]bool{comment: false})
+
+	// Banning twice is a conflict
+	if _, dbErr = db.BanUser(banning, banned, false); dbErr.Code != StateConflict {
+		t.Errorf("banning again gave code %d, want a conflict", dbErr.Code)
+	}
+}
+
+func TestBanUserPurge(t *testing.T) {
+	db, _ := newTestDatabase(t)
+	ids := createTestUsers(t, db, "banning", "banned", "other")
+	banning, banned, other := ids[0], ids[1], ids[2]
+
+	photo := insertTestPhoto(t, db, banning, PublicVisibility)
+	otherPhoto := insertTestPhoto(t, db, other, PublicVisibility)
+	if _, dbErr := db.LikePhoto(banned, photo, banning); dbErr.InternalError != nil {
+		t.Fatalf("liking photo: %v", dbErr.InternalError)
+	}
+
+	// A comment replied to only by the banned user, and by no one else
+	onlyOwnReplies := commentTestPhoto(t, db, banned, photo, 0)
+	commentTestPhoto(t, db, banned, photo, onlyOwnReplies)
+	// A placeholder of someone else whose only reply is of the banned user
+	placeholder := commentTestPhoto(t, db, other, photo, 0)
+	commentTestPhoto(t, db, banned, photo, placeholder)
+	if _, dbErr := db.DeleteComment(photo, other, placeholder, false); dbErr.InternalError != nil {
+		t.Fatalf("deleting comment: %v", dbErr.InternalError)
+	}
+	// A comment of the banned user replied to by someone else, and edited
+	repliedTo := commentTestPhoto(t, db, banned, photo, 0)
+	reply := commentTestPhoto(t, db, other, photo, repliedTo)
+	_, dbErr := db.EditComment(photo, banned, repliedTo, "edited", globaltime.Now().Add(-time.Hour))
+	if dbErr.InternalError != nil {
+		t.Fatalf("editing comment: %v", dbErr.InternalError)
+	}
+	// A comment of someone else whose reply by the banned user goes away, the comment stays
+	kept := commentTestPhoto(t, db, other, photo, 0)
+	commentTestPhoto(t, db, banned, photo, kept)
+	// Comments on the photos of other users are untouched
+	elsewhere := commentTestPhoto(t, db, banned, otherPhoto, 0)
+
+	ok, dbErr := db.BanUser(banning, banned, true)
+	if dbErr.InternalError != nil || !ok {
+		t.Fatalf("banning user: %t, %v", ok, dbErr.InternalError)
+	}
+
+	reaction, _ := db.photoReactionOf(photo, banned)
+	if reaction != "" {
+		t.Errorf("reaction is %q, want it purged", reaction)
+	}
+	assertCommentStates(t, db, photo, map[int64]bool{repliedTo: true, reply: false, kept: false})
+	assertCommentStates(t, db, otherPhoto, map[int64]bool{elsewhere: false})
+
+	// The placeholder keeps neither its content nor its edit
+	comments, dbErr := db.GetPhotoComments(photo, banning, banning, CommentsOrderRecent)
+	if dbErr.InternalError != nil {
+		t.Fatalf("listing comments: %v", dbErr.InternalError)
+	}
+	for _, comment := range comments {
+		if comment.Id == repliedTo && (comment.Content != "" || comment.EditedAt != "") {
+			t.Errorf("placeholder has content %q and edit time %q", comment.Content, comment.EditedAt)
+		}
+	}
+}
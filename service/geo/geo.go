@@ -0,0 +1,179 @@
+/*
+Package geo contains the geographic helpers used to store and query photo places.
+
+Places are indexed with geohashes: a geohash is a string where every character narrows down the cell of the previous
+one, so all the points inside a cell share the cell geohash as prefix. This allows to look for the points in an area
+with plain string range queries on an indexed column, which works on any SQL database (SQLite included).
+*/
+package geo
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	// MaxPrecision is the length of the geohashes stored for places (cells of about 5 meters)
+	MaxPrecision = 9
+
+	// EarthRadius is the mean radius of the Earth, in meters
+	EarthRadius = 6371000.0
+
+	base32 = "0123456789bcdefghjkmnpqrstuvwxyz"
+)
+
+// Box is an area delimited by two parallels and two meridians. Boxes crossing the antimeridian are not supported.
+type Box struct {
+	MinLat float64
+	MinLon float64
+	MaxLat float64
+	MaxLon float64
+}
+
+// IsValid returns true if the box corners are valid coordinates and the minimum corner is south-west of the maximum one.
+func (b Box) IsValid() bool {
+	return IsValidPoint(b.MinLat, b.MinLon) && IsValidPoint(b.MaxLat, b.MaxLon) &&
+		b.MinLat <= b.MaxLat && b.MinLon <= b.MaxLon
+}
+
+// Contains returns true if the point is inside the box.
+func (b Box) Contains(lat float64, lon float64) bool {
+	return lat >= b.MinLat && lat <= b.MaxLat && lon >= b.MinLon && lon <= b.MaxLon
+}
+
+// IsValidPoint returns true if latitude and longitude are in their ranges.
+func IsValidPoint(lat float64, lon float64) bool {
+	return lat >= -90 && lat <= 90 && lon >= -180 && lon <= 180
+}
+
+// Encode returns the geohash of the given point with the given number of characters.
+func Encode(lat float64, lon float64, precision int) string {
+	minLat, maxLat := -90.0, 90.0
+	minLon, maxLon := -180.0, 180.0
+
+	var hash strings.Builder
+	bit, ch := 0, 0
+	evenBit := true
+	for hash.Len() < precision {
+		// Bits alternate between longitude and latitude, starting from the longitude
+		if evenBit {
+			mid := (minLon + maxLon) / 2
+			if lon >= mid {
+				ch = ch<<1 | 1
+				minLon = mid
+			} else {
+				ch = ch << 1
+				maxLon = mid
+			}
+		} else {
+			mid := (minLat + maxLat) / 2
+			if lat >= mid {
+				ch = ch<<1 | 1
+				minLat = mid
+			} else {
+				ch = ch << 1
+				maxLat = mid
+			}
+		}
+		evenBit = !evenBit
+
+		bit++
+		if bit == 5 {
+			hash.WriteByte(base32[ch])
+			bit, ch = 0, 0
+		}
+	}
+
+	return hash.String()
+}
+
+// cellSize returns the height (latitude) and width (longitude) in degrees of the cells with the given precision.
+func cellSize(precision int) (float64, float64) {
+	latBits := (5 * precision) / 2
+	lonBits := 5*precision - latBits
+	return 180 / math.Pow(2, float64(latBits)), 360 / math.Pow(2, float64(lonBits))
+}
+
+// BoxPrecision returns the highest precision at which the box is covered by at most maxCells cells.
+func BoxPrecision(b Box, maxCells int) int {
+	for precision := MaxPrecision; precision > 1; precision-- {
+		height, width := cellSize(precision)
+		rows := math.Floor(b.MaxLat/height) - math.Floor(b.MinLat/height) + 1
+		columns := math.Floor(b.MaxLon/width) - math.Floor(b.MinLon/width) + 1
+		if rows*columns <= float64(maxCells) {
+			return precision
+		}
+	}
+
+	return 1
+}
+
+// Cover returns the geohashes of the cells with the given precision that intersect the box, sorted.
+func Cover(b Box, precision int) []string {
+	height, width := cellSize(precision)
+	cells := make(map[string]bool)
+
+	for lat := b.MinLat; ; lat += height {
+		lat = math.Min(lat, b.MaxLat)
+		for lon := b.MinLon; ; lon += width {
+			lon = math.Min(lon, b.MaxLon)
+			cells[Encode(lat, lon, precision)] = true
+			if lon >= b.MaxLon {
+				break
+			}
+		}
+		if lat >= b.MaxLat {
+			break
+		}
+	}
+
+	hashes := make([]string, 0, len(cells))
+	for hash := range cells {
+		hashes = append(hashes, hash)
+	}
+	sort.Strings(hashes)
+
+	return hashes
+}
+
+// PrefixUpperBound returns the smallest string greater than every geohash starting with prefix, so that the geohashes
+// with that prefix are the ones in the range [prefix, PrefixUpperBound(prefix)).
+func PrefixUpperBound(prefix string) string {
+	// '{' follows 'z', the last character of the geohash alphabet
+	return prefix + "{"
+}
+
+// BoxAround returns the smallest box containing the circle with the given center and radius in meters.
+func BoxAround(lat float64, lon float64, radius float64) Box {
+	deltaLat := radius / EarthRadius * 180 / math.Pi
+	b := Box{
+		MinLat: math.Max(lat-deltaLat, -90),
+		MaxLat: math.Min(lat+deltaLat, 90),
+		MinLon: -180,
+		MaxLon: 180,
+	}
+
+	// Near the poles a circle can span every meridian
+	cos := math.Cos(lat * math.Pi / 180)
+	if b.MinLat > -90 && b.MaxLat < 90 && cos > 0 {
+		deltaLon := deltaLat / cos
+		b.MinLon = math.Max(lon-deltaLon, -180)
+		b.MaxLon = math.Min(lon+deltaLon, 180)
+	}
+
+	return b
+}
+
+// Distance returns the great-circle distance in meters between two points.
+func Distance(lat1 float64, lon1 float64, lat2 float64, lon2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	deltaPhi := (lat2 - lat1) * math.Pi / 180
+	deltaLambda := (lon2 - lon1) * math.Pi / 180
+
+	a := math.Sin(deltaPhi/2)*math.Sin(deltaPhi/2) +
+		math.Cos(phi1)*math.Cos(phi2)*math.Sin(deltaLambda/2)*math.Sin(deltaLambda/2)
+
+	return 2 * EarthRadius * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
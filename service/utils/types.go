@@ -22,6 +22,21 @@ type HttpError struct {
 }
 
 const (
-	NotUserPhotoMessage string = "That user doesn't own that photo"
-	BannedMessage       string = "You are banned"
+	NotUserPhotoMessage          string = "That user doesn't own that photo"
+	NotUserAlbumMessage          string = "That user doesn't own that album"
+	NotUserStoryMessage          string = "That user doesn't own that story"
+	BannedMessage                string = "You are banned"
+	PrivateAccountMessage        string = "This account is private, only approved followers can see its content"
+	RestrictedPhotoMessage       string = "The owner of this photo didn't share it with you"
+	InvalidVisibilityMessage     string = "Visibility has to be public, followers, closeFriends or private"
+	CannotReplyMessage           string = "The parent comment can't be replied to"
+	InvalidCommentPolicyMessage  string = "Comment policy has to be everyone, followers, following or nobody"
+	CommentsFollowersOnlyMessage string = "Only the followers of the owner can comment this photo"
+	CommentsFollowingOnlyMessage string = "Only the people the owner follows can comment this photo"
+	CommentsDisabledMessage      string = "Comments are turned off for this photo"
+	InvalidReactionMessage       string = "Reaction has to be heart, laugh, wow, sad, fire or clap"
+)
+
+const (
+	MissingAltTextWarning string = "The photo has no alt text, consider adding one to make it accessible"
 )